@@ -4,8 +4,10 @@ import (
 	"auspire/models"
 	"auspire/services"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 )
 
 type BaziHandler struct {
@@ -13,14 +15,38 @@ type BaziHandler struct {
 	fortuneService   *services.FortuneService
 	xiyongshenService *services.XiYongShenService
 	baziyuceService   *services.BaziyuceService
+	graphService      *services.ChartGraphService
+	heHunService      *services.HeHunService
+	groupService      *services.GroupService
+	zeRiService       *services.ZeRiService
+	searchService     *services.SearchService
+	almanacService    *services.AlmanacService
+	ziWeiService      *services.ZiWeiService
+	qiMenService      *services.QiMenService
+	liuYaoService     *services.LiuYaoService
+	meiHuaService     *services.MeiHuaService
+	liuRenService     *services.LiuRenService
+	userService       *services.UserService
 }
 
-func NewBaziHandler() *BaziHandler {
+func NewBaziHandler(redisClient *redis.Client) *BaziHandler {
 	return &BaziHandler{
+		userService:       services.NewUserService(redisClient),
 		baziService:       services.NewBaziService(),
 		fortuneService:    services.NewFortuneService(),
 		xiyongshenService: services.NewXiYongShenService(),
 		baziyuceService:   services.NewBaziyuceService(),
+		graphService:      services.NewChartGraphService(),
+		heHunService:      services.NewHeHunService(),
+		groupService:      services.NewGroupService(),
+		zeRiService:       services.NewZeRiService(),
+		searchService:     services.NewSearchService(),
+		almanacService:    services.NewAlmanacService(),
+		ziWeiService:      services.NewZiWeiService(),
+		qiMenService:      services.NewQiMenService(),
+		liuYaoService:     services.NewLiuYaoService(),
+		meiHuaService:     services.NewMeiHuaService(),
+		liuRenService:     services.NewLiuRenService(),
 	}
 }
 
@@ -34,7 +60,45 @@ func (h *BaziHandler) CalculateBazi(c *gin.Context) {
 		return
 	}
 
-	response, err := h.baziService.CalculateBazi(req)
+	opts, err := h.calcOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.BaziResponse{
+			Name:  req.Name,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	response, err := h.baziService.CalculateBazi(req, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CalculateFromPillars 根据手工录入的四柱排盘
+func (h *BaziHandler) CalculateFromPillars(c *gin.Context) {
+	var req models.PillarsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.BaziResponse{
+			Error: "请求数据格式错误: " + err.Error(),
+		})
+		return
+	}
+
+	opts, err := h.calcOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.BaziResponse{
+			Name:  req.Name,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	response, err := h.baziService.CalculateFromPillars(req, opts)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, response)
 		return
@@ -110,7 +174,16 @@ func (h *BaziHandler) CalculateXiYongShen(c *gin.Context) {
 		return
 	}
 
-	result := h.xiyongshenService.Calculate(req.Bazi)
+	opts, err := h.calcOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.XiYongShenResult{
+			Name:  req.Name,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	result := h.xiyongshenService.Calculate(req.Bazi, opts)
 	result.Name = req.Name
 
 	c.JSON(http.StatusOK, result)
@@ -127,8 +200,337 @@ func (h *BaziHandler) AnalyzeBaziyuce(c *gin.Context) {
 		return
 	}
 
-	result := h.baziyuceService.Analyze(req.Bazi)
+	opts, err := h.calcOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.BaziyuceResult{
+			Name:  req.Name,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	result := h.baziyuceService.Analyze(req.Bazi, opts)
 	result.Name = req.Name
 
 	c.JSON(http.StatusOK, result)
-}
\ No newline at end of file
+}
+
+// BuildChartGraph 命局通根、透干、生克合冲关系图（?format=dot 输出 Graphviz DOT）
+func (h *BaziHandler) BuildChartGraph(c *gin.Context) {
+	var req models.GraphRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ChartGraph{
+			Error: "请求数据格式错误: " + err.Error(),
+		})
+		return
+	}
+
+	if len(req.Bazi) != 4 {
+		c.JSON(http.StatusBadRequest, models.ChartGraph{
+			Name:  req.Name,
+			Error: "生辰八字信息不完整",
+		})
+		return
+	}
+
+	opts, err := h.calcOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ChartGraph{
+			Name:  req.Name,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	graph := h.graphService.Build(req.Bazi, opts)
+	graph.Name = req.Name
+
+	if c.Query("format") == "dot" {
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(h.graphService.ToDOT(graph)))
+		return
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+// EvaluateHeHun 合婚：双方排盘后评估日干、夫妻宫、喜用互补、纳音及生肖
+func (h *BaziHandler) EvaluateHeHun(c *gin.Context) {
+	var req models.HeHunRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.HeHunResult{
+			Error: "请求数据格式错误: " + err.Error(),
+		})
+		return
+	}
+
+	opts, err := h.calcOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.HeHunResult{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.heHunService.Evaluate(req, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// AnalyzeGroup 多人关系矩阵：两两干支合冲及五行助损，并给出群体结论
+func (h *BaziHandler) AnalyzeGroup(c *gin.Context) {
+	var req models.GroupRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.GroupResult{
+			Error: "请求数据格式错误: " + err.Error(),
+		})
+		return
+	}
+
+	opts, err := h.calcOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.GroupResult{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.groupService.Analyze(req, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SelectZeRi 择日：按用事及当事人命局在日期范围内挑选吉日吉时
+func (h *BaziHandler) SelectZeRi(c *gin.Context) {
+	var req models.ZeRiRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ZeRiResult{
+			Error: "请求数据格式错误: " + err.Error(),
+		})
+		return
+	}
+
+	opts, err := h.calcOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ZeRiResult{
+			Purpose: req.Purpose,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	result, err := h.zeRiService.Select(req, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SearchCharts 按查询语句检索日期范围内满足条件的时间段
+func (h *BaziHandler) SearchCharts(c *gin.Context) {
+	var req models.SearchRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.SearchResult{
+			Error: "请求数据格式错误: " + err.Error(),
+		})
+		return
+	}
+
+	opts, err := h.calcOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.SearchResult{
+			Query: req.Query,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.searchService.Search(req, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetAlmanac 每日黄历，date 为 YYYY-MM-DD，缺省为当天
+func (h *BaziHandler) GetAlmanac(c *gin.Context) {
+	date := time.Now()
+	if value := c.Query("date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.AlmanacResult{
+				Date:  value,
+				Error: "日期格式错误: " + err.Error(),
+			})
+			return
+		}
+		date = parsed
+	}
+
+	result, err := h.almanacService.Daily(date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CalculateZiWei 紫微斗数排盘（农历生辰）
+func (h *BaziHandler) CalculateZiWei(c *gin.Context) {
+	var req models.ZiWeiRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ZiWeiResult{
+			Error: "请求数据格式错误: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.ziWeiService.Calculate(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CalculateQiMen 时家奇门排盘
+func (h *BaziHandler) CalculateQiMen(c *gin.Context) {
+	var req models.QiMenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.QiMenResult{
+			Error: "请求数据格式错误: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.qiMenService.Calculate(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CastLiuYao 六爻起卦：按铜钱数或起卦时刻成卦并装纳甲
+func (h *BaziHandler) CastLiuYao(c *gin.Context) {
+	var req models.LiuYaoRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.LiuYaoResult{
+			Error: "请求数据格式错误: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.liuYaoService.Cast(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CastMeiHua 梅花易数起卦：按农历年月日时或两数起卦并断体用
+func (h *BaziHandler) CastMeiHua(c *gin.Context) {
+	var req models.MeiHuaRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.MeiHuaResult{
+			Error: "请求数据格式错误: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.meiHuaService.Cast(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CalculateLiuRen 大六壬排盘：起天地盘、四课、三传及十二天将
+func (h *BaziHandler) CalculateLiuRen(c *gin.Context) {
+	var req models.LiuRenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.LiuRenResult{
+			Error: "请求数据格式错误: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.liuRenService.Calculate(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListSchools 列出可选的流派配置
+func (h *BaziHandler) ListSchools(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"default": services.DefaultSchoolProfile,
+		"schools": services.GetAllSchoolProfiles(),
+	})
+}
+
+// calcOptions 从查询参数读取计算选项（?explain=true 输出推导溯源，?school= 指定流派）
+//
+// 未指定流派时，已登录用户使用其默认流派，否则使用系统默认流派；
+// 用户保存的流派已下线或改名时退回系统默认流派，只有查询参数中的未知流派才报错。
+func (h *BaziHandler) calcOptions(c *gin.Context) (services.CalcOptions, error) {
+	school := c.Query("school")
+	profile, err := services.GetSchoolProfile(school)
+	if err != nil {
+		return services.CalcOptions{}, err
+	}
+	if school == "" {
+		if stored, err := services.GetSchoolProfile(h.userSchoolProfile(c)); err == nil {
+			profile = stored
+		}
+	}
+
+	return services.CalcOptions{
+		Explain: c.Query("explain") == "true",
+		Profile: profile,
+	}, nil
+}
+
+// userSchoolProfile 读取已登录用户的默认流派，匿名或查询失败时返回空
+func (h *BaziHandler) userSchoolProfile(c *gin.Context) string {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return ""
+	}
+	user, err := h.userService.GetUserByID(userID.(string))
+	if err != nil {
+		return ""
+	}
+	return user.SchoolProfile
+}
@@ -27,8 +27,9 @@ func main() {
 	jwtIssuer := getEnv("JWT_ISSUER", "auspire")
 
 	// Initialize handlers
-	baziHandler := handlers.NewBaziHandler()
+	baziHandler := handlers.NewBaziHandler(redisClient)
 	authHandler := handlers.NewAuthHandler(redisClient, jwtSecret, jwtIssuer)
+	jwtService := services.NewJWTService(jwtSecret, jwtIssuer)
 
 	r.Static("/static", "./static")
 	r.StaticFile("/", "./static/index.html")
@@ -41,15 +42,35 @@ func main() {
 		api.POST("/login", authHandler.Login)
 
 		// Public routes (no authentication required)
-		api.POST("/bazi", baziHandler.CalculateBazi)
-		api.POST("/xiyongshen", baziHandler.CalculateXiYongShen)
-		api.POST("/baziyuce", baziHandler.AnalyzeBaziyuce)
+		api.GET("/schools", baziHandler.ListSchools)
+		api.GET("/almanac", baziHandler.GetAlmanac)
+		api.POST("/ziwei", baziHandler.CalculateZiWei)
+		api.POST("/qimen", baziHandler.CalculateQiMen)
+		api.POST("/liuyao", baziHandler.CastLiuYao)
+		api.POST("/meihua", baziHandler.CastMeiHua)
+		api.POST("/liuren", baziHandler.CalculateLiuRen)
+
+		// Public routes that honour the logged-in user's default school
+		public := api.Group("/")
+		public.Use(middleware.OptionalAuthMiddleware(jwtService))
+		{
+			public.POST("/bazi", baziHandler.CalculateBazi)
+			public.POST("/xiyongshen", baziHandler.CalculateXiYongShen)
+			public.POST("/baziyuce", baziHandler.AnalyzeBaziyuce)
+			public.POST("/bazi/pillars", baziHandler.CalculateFromPillars)
+			public.POST("/bazi/graph", baziHandler.BuildChartGraph)
+			public.POST("/hehun", baziHandler.EvaluateHeHun)
+			public.POST("/group", baziHandler.AnalyzeGroup)
+			public.POST("/zeri", baziHandler.SelectZeRi)
+			public.POST("/search", baziHandler.SearchCharts)
+		}
 
 		// Protected routes (authentication required)
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware(services.NewJWTService(jwtSecret, jwtIssuer)))
+		protected.Use(middleware.AuthMiddleware(jwtService))
 		{
 			protected.GET("/profile", authHandler.GetProfile)
+			protected.PUT("/profile/school", authHandler.UpdateSchoolProfile)
 			protected.POST("/fortune", baziHandler.AnalyzeFortune)
 			protected.POST("/lifestages", baziHandler.AnalyzeLifeStages)
 		}
@@ -64,9 +85,23 @@ func main() {
 	log.Println("  注册: POST http://localhost:8080/api/register")
 	log.Println("  登录: POST http://localhost:8080/api/login")
 	log.Println("  个人资料: GET http://localhost:8080/api/profile")
+	log.Println("  设置默认流派: PUT http://localhost:8080/api/profile/school")
+	log.Println("  流派列表: GET http://localhost:8080/api/schools")
 	log.Println("  八字计算: POST http://localhost:8080/api/bazi")
 	log.Println("  喜用神计算: POST http://localhost:8080/api/xiyongshen")
 	log.Println("  八字综合分析: POST http://localhost:8080/api/baziyuce")
+	log.Println("  四柱录入排盘: POST http://localhost:8080/api/bazi/pillars")
+	log.Println("  命局关系图: POST http://localhost:8080/api/bazi/graph")
+	log.Println("  合婚: POST http://localhost:8080/api/hehun")
+	log.Println("  多人关系矩阵: POST http://localhost:8080/api/group")
+	log.Println("  择日: POST http://localhost:8080/api/zeri")
+	log.Println("  命局检索: POST http://localhost:8080/api/search")
+	log.Println("  黄历: GET http://localhost:8080/api/almanac?date=2026-10-18")
+	log.Println("  紫微斗数: POST http://localhost:8080/api/ziwei")
+	log.Println("  奇门遁甲: POST http://localhost:8080/api/qimen")
+	log.Println("  六爻: POST http://localhost:8080/api/liuyao")
+	log.Println("  梅花易数: POST http://localhost:8080/api/meihua")
+	log.Println("  大六壬: POST http://localhost:8080/api/liuren")
 	log.Println("  运势分析: POST http://localhost:8080/api/fortune")
 	log.Println("  人生阶段分析: POST http://localhost:8080/api/lifestages")
 	log.Println("健康检查: http://localhost:8080/health")
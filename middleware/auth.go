@@ -37,4 +37,18 @@ func AuthMiddleware(jwtService *services.JWTService) gin.HandlerFunc {
 		c.Set("username", claims.Username)
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// OptionalAuthMiddleware 可选认证：携带有效 token 时写入用户信息，否则按匿名请求放行
+func OptionalAuthMiddleware(jwtService *services.JWTService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenParts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(tokenParts) == 2 && tokenParts[0] == "Bearer" {
+			if claims, err := jwtService.ParseToken(tokenParts[1]); err == nil {
+				c.Set("user_id", claims.UserID)
+				c.Set("username", claims.Username)
+			}
+		}
+		c.Next()
+	}
+}
@@ -1,33 +1,174 @@
 package models
 
 type BaziRequest struct {
-	Name      string `json:"name" binding:"required"`
-	BirthDate string `json:"birthDate" binding:"required"`
-	BirthTime string `json:"birthTime" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+	BirthDate   string `json:"birthDate" binding:"required"`
+	BirthTime   string `json:"birthTime" binding:"required"`
+	Gender      string `json:"gender,omitempty" binding:"omitempty,oneof=男 女"`              // 性别，排大运时必填
+	LiuNianYear int    `json:"liuNianYear,omitempty" binding:"omitempty,min=1900,max=2100"` // 流年起始年，提供时排十年流年
+}
+
+// PillarsRequest 手工录入四柱请求，如 "庚午 甲申 癸未 丁巳"
+type PillarsRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Pillars     string `json:"pillars" binding:"required"`
+	Gender      string `json:"gender,omitempty" binding:"omitempty,oneof=男 女"`              // 性别，标注六亲时需要
+	LiuNianYear int    `json:"liuNianYear,omitempty" binding:"omitempty,min=1900,max=2100"` // 流年起始年，提供时排十年流年
 }
 
 type BaziColumn struct {
-	Gan       string            `json:"gan"`
-	Zhi       string            `json:"zhi"`
-	GanWuXing string            `json:"ganWuXing"`
-	ZhiWuXing string            `json:"zhiWuXing"`
-	ZhuXing   string            `json:"zhuXing,omitempty"`    // 主星
-	CangGan   []string          `json:"cangGan,omitempty"`   // 藏干
-	FuXing    []string          `json:"fuXing,omitempty"`    // 副星
-	NaYin     string            `json:"naYin,omitempty"`     // 纳音
-	XingYun   string            `json:"xingYun,omitempty"`   // 星运
-	ZiZuo     string            `json:"ziZuo,omitempty"`     // 自坐
-	KongWang  bool              `json:"kongWang,omitempty"`  // 空亡
-	ShenSha   map[string]string `json:"shenSha,omitempty"`   // 神煞
+	Gan       string        `json:"gan"`
+	Zhi       string        `json:"zhi"`
+	GanWuXing string        `json:"ganWuXing"`
+	ZhiWuXing string        `json:"zhiWuXing"`
+	ZhuXing   string        `json:"zhuXing,omitempty"`  // 主星
+	LiuQin    string        `json:"liuQin,omitempty"`   // 天干六亲（需提供性别）
+	CangGan   []CangGanItem `json:"cangGan,omitempty"`  // 藏干（含副星十神）
+	NaYin     string        `json:"naYin,omitempty"`    // 纳音
+	XingYun   string        `json:"xingYun,omitempty"`  // 星运
+	ZiZuo     string        `json:"ziZuo,omitempty"`    // 自坐
+	KongWang  bool          `json:"kongWang,omitempty"` // 空亡（日柱旬空）
+	NianKong  bool          `json:"nianKong,omitempty"` // 年空（年柱旬空）
+	ShenSha   []ShenShaItem `json:"shenSha,omitempty"`  // 神煞
+}
+
+// CangGanItem 地支藏干
+type CangGanItem struct {
+	Gan     string `json:"gan"`
+	Role    string `json:"role"`    // 本气/中气/余气
+	Weight  int    `json:"weight"`  // 力量占比（百分比）
+	ShiShen string `json:"shiShen"` // 对日干的十神（副星）
+	WuXing  string `json:"wuXing"`
+	LiuQin  string `json:"liuQin,omitempty"` // 六亲（需提供性别）
+}
+
+// LiuQinEntry 六亲汇总项
+type LiuQinEntry struct {
+	Relative    string           `json:"relative"`  // 亲属
+	Star        string           `json:"star"`      // 六亲星（十神）
+	Positions   []LiuQinPosition `json:"positions"` // 所在位置
+	Score       int              `json:"score"`
+	Strength    string           `json:"strength"` // 不现/弱/中/旺
+	Description string           `json:"description"`
+}
+
+// LiuQinPosition 六亲星所在位置
+type LiuQinPosition struct {
+	Pillar string `json:"pillar"`
+	Palace string `json:"palace"` // 宫位
+	Gan    string `json:"gan"`
+	Source string `json:"source"` // 天干 或 藏干本气/中气/余气
+}
+
+// ShenShaItem 神煞命中项
+type ShenShaItem struct {
+	Name        string `json:"name"`
+	Category    string `json:"category"` // 吉/凶/中性
+	Description string `json:"description"`
+	Basis       string `json:"basis"`            // 查法基准，如 日干、年支三合
+	Match       string `json:"match"`            // 命中的干支
+	Pillar      string `json:"pillar,omitempty"` // 所在柱（整盘汇总时填写）
 }
 
 type BaziResponse struct {
 	Name            string            `json:"name"`
 	Bazi            []BaziColumn      `json:"bazi"`
 	ShiErChangSheng map[string]string `json:"shiErChangSheng,omitempty"`
+	NaYin           *NaYinAnalysis    `json:"naYin,omitempty"`          // 纳音关系
+	KongWang        *KongWangAnalysis `json:"kongWang,omitempty"`       // 空亡分析
+	LiuQin          []LiuQinEntry     `json:"liuQin,omitempty"`         // 六亲（需提供性别）
+	DaYun           *DaYunInfo        `json:"daYun,omitempty"`          // 大运（需提供性别）
+	LiuNian         []LiuNianPillar   `json:"liuNian,omitempty"`        // 流年（需提供 liuNianYear）
+	School          string            `json:"school,omitempty"`         // 所用流派（名称@版本）
+	ChangShengMode  string            `json:"changShengMode,omitempty"` // 十二长生取法（星运、自坐、十二长生）
+	Trace           []TraceEntry      `json:"trace,omitempty"`          // 推导溯源（explain=true）
 	Error           string            `json:"error,omitempty"`
 }
 
+// NaYinAnalysis 纳音关系分析
+type NaYinAnalysis struct {
+	Pillars   map[string]string `json:"pillars"`   // 各柱纳音
+	Relations []NaYinRelation   `json:"relations"` // 各柱纳音对日柱纳音的生克
+	Summary   string            `json:"summary"`
+}
+
+// NaYinRelation 两柱纳音五行的生克关系（以日柱纳音为我）
+type NaYinRelation struct {
+	Pillar      string `json:"pillar"`   // 对方柱，如 年柱
+	NaYin       string `json:"naYin"`    // 对方纳音
+	DayNaYin    string `json:"dayNaYin"` // 日柱纳音
+	Relation    string `json:"relation"` // 生我/我生/克我/我克/比和
+	Verdict     string `json:"verdict"`  // 吉/凶/平
+	Description string `json:"description"`
+}
+
+// DaYunInfo 大运
+type DaYunInfo struct {
+	Direction      string        `json:"direction"`      // 顺行/逆行
+	StartAgeYears  int           `json:"startAgeYears"`  // 起运岁数
+	StartAgeMonths int           `json:"startAgeMonths"` // 起运月数
+	Description    string        `json:"description"`
+	Pillars        []DaYunPillar `json:"pillars"`
+}
+
+// KongWangAnalysis 空亡分析
+type KongWangAnalysis struct {
+	RiKong   []string          `json:"riKong"`   // 日柱旬空
+	NianKong []string          `json:"nianKong"` // 年柱旬空
+	Variants []KongWangVariant `json:"variants"` // 截路空亡、四大空亡
+}
+
+// KongWangVariant 截路空亡、四大空亡等特殊空亡
+type KongWangVariant struct {
+	Name        string `json:"name"`
+	Pillar      string `json:"pillar"` // 所在柱
+	Basis       string `json:"basis"`  // 查法基准
+	Description string `json:"description"`
+}
+
+// KongWangEvent 岁运对原局空亡的作用
+type KongWangEvent struct {
+	Type        string `json:"type"`   // 填实 或 冲空
+	Pillar      string `json:"pillar"` // 原局落空之柱
+	Zhi         string `json:"zhi"`    // 原局落空之支
+	Description string `json:"description"`
+}
+
+// DaYunPillar 大运柱
+type DaYunPillar struct {
+	Gan            string          `json:"gan"`
+	Zhi            string          `json:"zhi"`
+	NaYin          string          `json:"naYin"`
+	StartAge       int             `json:"startAge"`
+	StartYear      int             `json:"startYear"`
+	EndYear        int             `json:"endYear"`
+	NaYinRelation  string          `json:"naYinRelation"`            // 大运纳音对日柱纳音
+	KongWang       []string        `json:"kongWang,omitempty"`       // 大运地支落日空、年空
+	KongWangEvents []KongWangEvent `json:"kongWangEvents,omitempty"` // 填实、冲空原局空亡
+}
+
+// LiuNianPillar 流年柱
+type LiuNianPillar struct {
+	Year           int             `json:"year"`
+	Gan            string          `json:"gan"`
+	Zhi            string          `json:"zhi"`
+	NaYin          string          `json:"naYin"`
+	Age            int             `json:"age,omitempty"`            // 周岁，录入四柱排盘时无
+	NaYinRelation  string          `json:"naYinRelation"`            // 流年纳音对日柱纳音
+	KongWang       []string        `json:"kongWang,omitempty"`       // 流年地支落日空、年空
+	KongWangEvents []KongWangEvent `json:"kongWangEvents,omitempty"` // 填实、冲空原局空亡
+}
+
+// TraceEntry 推导溯源记录
+type TraceEntry struct {
+	Field  string            `json:"field"`            // 推导字段，如 zhuXing、shenSha
+	Pillar string            `json:"pillar,omitempty"` // 所在柱
+	Rule   string            `json:"rule"`             // 所用规则
+	Inputs map[string]string `json:"inputs"`           // 规则输入
+	Match  string            `json:"match,omitempty"`  // 命中的表项
+	Value  string            `json:"value"`            // 推导结果
+}
+
 type FortuneRequest struct {
 	Name      string       `json:"name" binding:"required"`
 	Bazi      []BaziColumn `json:"bazi" binding:"required"`
@@ -72,14 +213,50 @@ type XiYongShenRequest struct {
 // XiYongShenResult 喜用神计算结果
 type XiYongShenResult struct {
 	Name          string            `json:"name"`
-	RiZhu         string            `json:"riZhu"`                 // 日主
-	RiZhuStrength string            `json:"riZhuStrength"`         // 日主强弱
-	WuXingScores  map[string]int    `json:"wuXingScores"`          // 五行得分
-	XiYongShen    string            `json:"xiYongShen"`            // 喜用神
-	Logic         []string          `json:"logic"`                 // 计算逻辑
+	RiZhu         string            `json:"riZhu"`             // 日主
+	RiZhuStrength string            `json:"riZhuStrength"`     // 日主强弱
+	WuXingScores  map[string]int    `json:"wuXingScores"`      // 五行得分
+	XiYongShen    string            `json:"xiYongShen"`        // 喜用神
+	Logic         []string          `json:"logic"`             // 计算逻辑
+	WuXingRoles   map[string]string `json:"wuXingRoles"`       // 五行角色（用神/喜神/忌神/仇神/闲神）
+	PillarRoles   []PillarRole      `json:"pillarRoles"`       // 四柱干支角色
+	TiaoHou       *TiaoHouResult    `json:"tiaoHou,omitempty"` // 调候用神
+	School        string            `json:"school,omitempty"`  // 所用流派（名称@版本）
+	Trace         []TraceEntry      `json:"trace,omitempty"`   // 推导溯源（explain=true）
 	Error         string            `json:"error,omitempty"`
 }
 
+// PillarRole 四柱干支的五行角色
+type PillarRole struct {
+	Pillar  string `json:"pillar"`
+	Gan     string `json:"gan"`
+	GanRole string `json:"ganRole"`
+	Zhi     string `json:"zhi"`
+	ZhiRole string `json:"zhiRole"`
+}
+
+// TiaoHouShen 调候用神及其在命局中的出现情况
+type TiaoHouShen struct {
+	Gan       string   `json:"gan"`
+	WuXing    string   `json:"wuXing"`
+	Role      string   `json:"role"`                // 主用/次用
+	Exposed   bool     `json:"exposed"`             // 天干透出
+	Hidden    bool     `json:"hidden"`              // 地支藏干
+	Positions []string `json:"positions,omitempty"` // 出现位置
+}
+
+// TiaoHouResult 调候用神分析结果
+type TiaoHouResult struct {
+	RiZhu     string        `json:"riZhu"`     // 日主
+	YueZhi    string        `json:"yueZhi"`    // 月支
+	Primary   []string      `json:"primary"`   // 主用调候
+	Secondary []string      `json:"secondary"` // 次用调候
+	Items     []TiaoHouShen `json:"items"`
+	Satisfied bool          `json:"satisfied"` // 主用调候是否已见于命局
+	Summary   string        `json:"summary"`
+	Source    string        `json:"source"` // 出处
+}
+
 // BaziyuceRequest 四柱八字综合分析请求
 type BaziyuceRequest struct {
 	Name string       `json:"name" binding:"required"`
@@ -92,9 +269,271 @@ type AnalysisStep struct {
 	Content []string `json:"content"`
 }
 
+// PalaceAnalysis 宫位分析
+type PalaceAnalysis struct {
+	Pillar     string   `json:"pillar"`
+	Palace     string   `json:"palace"`     // 宫位
+	Domain     string   `json:"domain"`     // 所主
+	ShiShen    []string `json:"shiShen"`    // 所临十神（天干及地支本气）
+	ChangSheng string   `json:"changSheng"` // 日主在本宫的十二长生
+	ShenSha    []string `json:"shenSha"`
+	KongWang   bool     `json:"kongWang"`
+	Relations  []string `json:"relations"` // 与他柱的冲合
+	Findings   []string `json:"findings"`  // 断语
+}
+
+// ShiShenProfile 十神统计与性格
+type ShiShenProfile struct {
+	Stats   []ShiShenStat  `json:"stats"`
+	Flags   []string       `json:"flags"`  // 缺失、过旺及组合，如 无官杀、伤官旺、官杀混杂
+	Traits  []ShiShenTrait `json:"traits"` // 性格特质
+	Summary string         `json:"summary"`
+}
+
+// ShiShenStat 单个十神的统计
+type ShiShenStat struct {
+	ShiShen     string `json:"shiShen"`
+	Count       int    `json:"count"`
+	StemCount   int    `json:"stemCount"`   // 天干透出次数（日干除外）
+	HiddenCount int    `json:"hiddenCount"` // 地支藏干次数
+	Score       int    `json:"score"`       // 加权力量
+	Status      string `json:"status"`      // 透出有根/透出/藏/不现
+	Level       string `json:"level"`       // 旺/中/弱/无
+}
+
+// ShiShenTrait 十神性格特质
+type ShiShenTrait struct {
+	Source    string   `json:"source"`    // 十神或十神分组
+	Condition string   `json:"condition"` // 取用条件，如 透出有根且旺、缺失
+	Positive  []string `json:"positive"`
+	Negative  []string `json:"negative"`
+}
+
 // BaziyuceResult 四柱八字综合分析结果
 type BaziyuceResult struct {
-	Name  string         `json:"name"`
-	Steps []AnalysisStep `json:"steps"`
-	Error string         `json:"error,omitempty"`
-}
\ No newline at end of file
+	Name           string           `json:"name"`
+	Steps          []AnalysisStep   `json:"steps"`
+	TiaoHou        *TiaoHouResult   `json:"tiaoHou,omitempty"`        // 调候用神
+	NaYin          *NaYinAnalysis   `json:"naYin,omitempty"`          // 纳音关系
+	Palaces        []PalaceAnalysis `json:"palaces,omitempty"`        // 宫位分析
+	Personality    *ShiShenProfile  `json:"personality,omitempty"`    // 十神统计与性格
+	School         string           `json:"school,omitempty"`         // 所用流派（名称@版本）
+	ChangShengMode string           `json:"changShengMode,omitempty"` // 十二长生取法（月令状态）
+	Trace          []TraceEntry     `json:"trace,omitempty"`          // 推导溯源（explain=true）
+	Error          string           `json:"error,omitempty"`
+}
+
+// GraphRequest 命局关系图请求
+type GraphRequest struct {
+	Name string       `json:"name" binding:"required"`
+	Bazi []BaziColumn `json:"bazi" binding:"required"`
+}
+
+// GraphNode 关系图节点（天干、地支、藏干）
+type GraphNode struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"` // 天干/地支/藏干
+	Label  string `json:"label"`
+	Pillar string `json:"pillar"` // 所在柱
+	WuXing string `json:"wuXing"`
+}
+
+// GraphEdge 关系图边（藏、通根、透干、生、克、合、冲）
+type GraphEdge struct {
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	Type        string  `json:"type"`
+	Strength    string  `json:"strength"` // 强/中/弱
+	Weight      float64 `json:"weight"`
+	Description string  `json:"description"`
+}
+
+// ChartGraph 命局关系图
+type ChartGraph struct {
+	Name   string      `json:"name"`
+	Nodes  []GraphNode `json:"nodes"`
+	Edges  []GraphEdge `json:"edges"`
+	School string      `json:"school,omitempty"` // 所用流派（名称@版本）
+	Error  string      `json:"error,omitempty"`
+}
+
+// HeHunRequest 合婚请求，双方均按出生时间排盘
+type HeHunRequest struct {
+	PersonA BaziRequest `json:"personA" binding:"required"`
+	PersonB BaziRequest `json:"personB" binding:"required"`
+}
+
+// HeHunItem 合婚单项评分
+type HeHunItem struct {
+	Aspect   string   `json:"aspect"` // 日干/夫妻宫/喜用互补/纳音/生肖
+	Score    int      `json:"score"`
+	MaxScore int      `json:"maxScore"`
+	Reasons  []string `json:"reasons"`
+}
+
+// HeHunResult 合婚结果
+type HeHunResult struct {
+	PersonA  *BaziResponse `json:"personA,omitempty"`
+	PersonB  *BaziResponse `json:"personB,omitempty"`
+	Items    []HeHunItem   `json:"items"`
+	Score    int           `json:"score"`    // 总分
+	MaxScore int           `json:"maxScore"` // 满分
+	Level    string        `json:"level"`    // 上等婚/中等婚/下等婚
+	Summary  string        `json:"summary"`
+	School   string        `json:"school,omitempty"` // 所用流派（名称@版本）
+	Error    string        `json:"error,omitempty"`
+}
+
+// GroupRequest 多人关系矩阵请求（家庭、创业团队等），成员均按出生时间排盘
+type GroupRequest struct {
+	Members []BaziRequest `json:"members" binding:"required,min=2,max=20,dive"`
+}
+
+// GroupMember 成员命局概要
+type GroupMember struct {
+	Name          string         `json:"name"`
+	Bazi          []BaziColumn   `json:"bazi"`
+	RiZhu         string         `json:"riZhu"`         // 日主
+	RiZhuStrength string         `json:"riZhuStrength"` // 日主强弱
+	XiYongShen    string         `json:"xiYongShen"`    // 喜用神
+	WuXingCount   map[string]int `json:"wuXingCount"`   // 八字干支五行个数
+}
+
+// GroupInfluence 一方八字五行对另一方的助益或损害
+type GroupInfluence struct {
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Favorable   []string `json:"favorable"`   // 为对方用神、喜神的干支
+	Unfavorable []string `json:"unfavorable"` // 为对方忌神、仇神的干支
+	Effect      string   `json:"effect"`      // 助/损/平
+	Description string   `json:"description"`
+}
+
+// GroupPair 两两成员的干支互动
+type GroupPair struct {
+	A            int            `json:"a"` // 成员序号
+	B            int            `json:"b"`
+	GanRelations []string       `json:"ganRelations"` // 天干五合、相冲
+	ZhiRelations []string       `json:"zhiRelations"` // 地支六合、三合、六冲、六害
+	AToB         GroupInfluence `json:"aToB"`
+	BToA         GroupInfluence `json:"bToA"`
+	Score        int            `json:"score"` // 互动综合分，正为和谐，负为冲突
+}
+
+// GroupResult 多人关系矩阵结果
+type GroupResult struct {
+	Members      []GroupMember  `json:"members"`
+	Pairs        []GroupPair    `json:"pairs"`
+	Matrix       [][]int        `json:"matrix"`           // matrix[i][j]：成员 j 对成员 i 的五行助益（有利字数减不利字数）
+	WuXingTotals map[string]int `json:"wuXingTotals"`     // 全体八字干支五行个数
+	Findings     []string       `json:"findings"`         // 群体层面的结论
+	School       string         `json:"school,omitempty"` // 所用流派（名称@版本）
+	Error        string         `json:"error,omitempty"`
+}
+
+// ZeRiRequest 择日请求
+type ZeRiRequest struct {
+	Purpose      string        `json:"purpose" binding:"required,oneof=嫁娶 搬家 开业 签约"` // 用事
+	StartDate    string        `json:"startDate" binding:"required"`
+	EndDate      string        `json:"endDate" binding:"required"`
+	Participants []BaziRequest `json:"participants,omitempty" binding:"omitempty,dive"` // 当事人（可选）
+	Limit        int           `json:"limit,omitempty"`                                 // 返回候选日数，默认 10
+}
+
+// ZeRiHour 候选吉时
+type ZeRiHour struct {
+	Zhi     string   `json:"zhi"`
+	GanZhi  string   `json:"ganZhi"`
+	Time    string   `json:"time"` // 钟点范围
+	God     string   `json:"god"`  // 黄道值神
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// ZeRiDay 候选吉日
+type ZeRiDay struct {
+	Date     string     `json:"date"`
+	Year     string     `json:"year"`     // 年柱
+	Month    string     `json:"month"`    // 月柱
+	Day      string     `json:"day"`      // 日柱
+	JianChu  string     `json:"jianChu"`  // 建除十二神
+	God      string     `json:"god"`      // 黄道黑道值神
+	HuangDao bool       `json:"huangDao"` // 是否黄道日
+	Score    int        `json:"score"`
+	Reasons  []string   `json:"reasons"`
+	Hours    []ZeRiHour `json:"hours"` // 当日吉时
+}
+
+// ZeRiResult 择日结果
+type ZeRiResult struct {
+	Purpose    string    `json:"purpose"`
+	StartDate  string    `json:"startDate"`
+	EndDate    string    `json:"endDate"`
+	Candidates []ZeRiDay `json:"candidates"`       // 按得分排序的候选日
+	Excluded   int       `json:"excluded"`         // 排除的天数
+	School     string    `json:"school,omitempty"` // 所用流派（名称@版本）
+	Error      string    `json:"error,omitempty"`
+}
+
+// SearchRequest 按条件检索日期范围内的命局
+type SearchRequest struct {
+	Query     string `json:"query" binding:"required"` // 查询语句，如 dayGan=甲 AND monthZhi IN (寅,卯)
+	StartDate string `json:"startDate" binding:"required"`
+	EndDate   string `json:"endDate" binding:"required"`
+	Limit     int    `json:"limit,omitempty"` // 返回时间段数上限，默认 50
+}
+
+// SearchWindow 满足条件的连续时间段（按时辰合并）
+type SearchWindow struct {
+	Start   string   `json:"start"`   // 开始时间 YYYY-MM-DD HH:MM
+	End     string   `json:"end"`     // 结束时间 YYYY-MM-DD HH:MM
+	Pillars []string `json:"pillars"` // 时间段内的四柱（按出现顺序去重）
+}
+
+// SearchResult 命局检索结果
+type SearchResult struct {
+	Query     string         `json:"query"`
+	Windows   []SearchWindow `json:"windows"`
+	Matched   int            `json:"matched"`          // 命中的时辰数
+	Truncated bool           `json:"truncated"`        // 时间段超过上限被截断
+	School    string         `json:"school,omitempty"` // 所用流派（名称@版本）
+	Error     string         `json:"error,omitempty"`
+}
+
+// AlmanacHour 黄历时辰
+type AlmanacHour struct {
+	Zhi        string `json:"zhi"`
+	GanZhi     string `json:"ganZhi"`
+	Time       string `json:"time"`       // 钟点范围
+	God        string `json:"god"`        // 黄道黑道值神
+	HuangDao   bool   `json:"huangDao"`   // 是否黄道时
+	Chong      string `json:"chong"`      // 时冲生肖
+	Auspicious bool   `json:"auspicious"` // 吉时：黄道且不冲日支
+}
+
+// AlmanacResult 每日黄历
+type AlmanacResult struct {
+	Date      string        `json:"date"`
+	Weekday   string        `json:"weekday"`
+	Year      string        `json:"year"`      // 年柱
+	Month     string        `json:"month"`     // 月柱
+	Day       string        `json:"day"`       // 日柱
+	ShengXiao string        `json:"shengXiao"` // 年生肖
+	NaYin     string        `json:"naYin"`     // 日柱纳音
+	JieQi     string        `json:"jieQi"`     // 当日所在节气
+	JieQiDate string        `json:"jieQiDate"` // 交节日期
+	JianChu   string        `json:"jianChu"`   // 建除十二神
+	God       string        `json:"god"`       // 黄道黑道值神
+	HuangDao  bool          `json:"huangDao"`  // 是否黄道日
+	Xiu       string        `json:"xiu"`       // 值日星宿
+	XiuLuck   string        `json:"xiuLuck"`   // 星宿吉凶
+	Chong     string        `json:"chong"`     // 日冲，如 冲马(戊午)
+	Sha       string        `json:"sha"`       // 煞方，如 煞南
+	TaiShen   string        `json:"taiShen"`   // 胎神占方
+	SuiPo     bool          `json:"suiPo"`     // 是否岁破日
+	Hours     []AlmanacHour `json:"hours"`     // 十二时辰吉凶
+	JiShi     []string      `json:"jiShi"`     // 吉时地支
+	Yi        []string      `json:"yi"`        // 宜
+	Ji        []string      `json:"ji"`        // 忌
+	Error     string        `json:"error,omitempty"`
+}
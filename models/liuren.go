@@ -0,0 +1,51 @@
+package models
+
+// LiuRenRequest 大六壬排盘请求
+type LiuRenRequest struct {
+	Date string `json:"date" binding:"required"` // YYYY-MM-DD
+	Time string `json:"time" binding:"required"` // HH:MM
+}
+
+// LiuRenPosition 天地盘一位
+type LiuRenPosition struct {
+	Earth   string `json:"earth"`   // 地盘支
+	Heaven  string `json:"heaven"`  // 所临天盘之神
+	General string `json:"general"` // 天盘神所乘天将
+}
+
+// LiuRenLesson 四课之一
+type LiuRenLesson struct {
+	Name     string `json:"name"`               // 一课……四课
+	Upper    string `json:"upper"`              // 上神
+	Lower    string `json:"lower"`              // 下神（一课为日干）
+	General  string `json:"general"`            // 上神所乘天将
+	Relation string `json:"relation,omitempty"` // 下贼上、上克下
+}
+
+// LiuRenTransmission 三传之一
+type LiuRenTransmission struct {
+	Name    string `json:"name"`             // 初传、中传、末传
+	Zhi     string `json:"zhi"`              // 地支
+	DunGan  string `json:"dunGan,omitempty"` // 旬遁之干，旬空则无
+	LiuQin  string `json:"liuQin"`           // 以日干为我论六亲
+	General string `json:"general"`          // 所乘天将
+}
+
+// LiuRenResult 大六壬盘
+type LiuRenResult struct {
+	Date          string               `json:"date"`
+	Time          string               `json:"time"`
+	Bazi          []string             `json:"bazi"`      // 年、月、日、时柱
+	ZhongQi       string               `json:"zhongQi"`   // 所值中气
+	YueJiang      string               `json:"yueJiang"`  // 月将，如 亥登明
+	DayNight      string               `json:"dayNight"`  // 昼/夜
+	GuiRen        string               `json:"guiRen"`    // 贵人所在天盘支
+	Direction     string               `json:"direction"` // 天将顺/逆布
+	Plate         []LiuRenPosition     `json:"plate"`     // 地盘子至亥
+	Lessons       []LiuRenLesson       `json:"lessons"`
+	Method        string               `json:"method"`      // 发用课体，如 贼克、涉害
+	Description   string               `json:"description"` // 取传说明
+	Transmissions []LiuRenTransmission `json:"transmissions"`
+	XunKong       []string             `json:"xunKong"` // 日柱旬空
+	Error         string               `json:"error,omitempty"`
+}
@@ -0,0 +1,59 @@
+package models
+
+// LiuYaoRequest 六爻起卦请求
+type LiuYaoRequest struct {
+	Question string `json:"question,omitempty"`                        // 所问之事
+	Coins    []int  `json:"coins,omitempty" binding:"omitempty,len=6"` // 自初爻起六次铜钱数（6-9），缺省以时间起卦
+	Date     string `json:"date,omitempty"`                            // 起卦日期 YYYY-MM-DD，缺省为当天
+	Time     string `json:"time,omitempty"`                            // 起卦时间 HH:MM，缺省为当前时刻
+}
+
+// LiuYaoHexagram 卦
+type LiuYaoHexagram struct {
+	Name         string `json:"name"`
+	Upper        string `json:"upper"`        // 上卦
+	Lower        string `json:"lower"`        // 下卦
+	Palace       string `json:"palace"`       // 所属八宫
+	PalaceWuXing string `json:"palaceWuXing"` // 宫五行
+	Generation   string `json:"generation"`   // 本宫、一世……游魂、归魂
+}
+
+// LiuYaoChange 动爻所化之爻
+type LiuYaoChange struct {
+	Yang     bool   `json:"yang"`
+	GanZhi   string `json:"ganZhi"`
+	WuXing   string `json:"wuXing"`
+	LiuQin   string `json:"liuQin"`
+	Relation string `json:"relation"` // 回头生、回头克、比和、化泄、化耗
+}
+
+// LiuYaoLine 爻（自初爻起）
+type LiuYaoLine struct {
+	Position int           `json:"position"` // 1-6
+	Yang     bool          `json:"yang"`
+	Moving   bool          `json:"moving"`
+	Coin     string        `json:"coin"` // 老阴、少阳、少阴、老阳
+	GanZhi   string        `json:"ganZhi"`
+	WuXing   string        `json:"wuXing"`
+	LiuQin   string        `json:"liuQin"`
+	LiuShen  string        `json:"liuShen"`
+	ShiYing  string        `json:"shiYing,omitempty"` // 世/应
+	Notes    []string      `json:"notes"`             // 月建、日辰作用及旬空
+	FuShen   string        `json:"fuShen,omitempty"`  // 伏神，如 妻财甲寅木
+	Change   *LiuYaoChange `json:"change,omitempty"`
+}
+
+// LiuYaoResult 六爻卦盘
+type LiuYaoResult struct {
+	Question string          `json:"question,omitempty"`
+	Method   string          `json:"method"` // 铜钱/时间（月建日辰数）
+	Date     string          `json:"date"`
+	Time     string          `json:"time"`
+	Month    string          `json:"month"` // 月建
+	Day      string          `json:"day"`   // 日辰
+	XunKong  []string        `json:"xunKong"`
+	Original LiuYaoHexagram  `json:"original"`          // 本卦
+	Changed  *LiuYaoHexagram `json:"changed,omitempty"` // 变卦，无动爻时省略
+	Lines    []LiuYaoLine    `json:"lines"`
+	Error    string          `json:"error,omitempty"`
+}
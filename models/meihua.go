@@ -0,0 +1,48 @@
+package models
+
+// MeiHuaRequest 梅花易数起卦请求：给出两数为数字起卦，否则以农历年月日时起卦
+type MeiHuaRequest struct {
+	LunarYear  int    `json:"lunarYear,omitempty" binding:"omitempty,min=1900,max=2100"`
+	LunarMonth int    `json:"lunarMonth,omitempty" binding:"omitempty,min=1,max=12"`
+	LunarDay   int    `json:"lunarDay,omitempty" binding:"omitempty,min=1,max=30"`
+	Time       string `json:"time,omitempty"`                                               // HH:MM，时间起卦必填
+	Numbers    []int  `json:"numbers,omitempty" binding:"omitempty,dive,min=1,max=1000000"` // 数字起卦的两个数
+}
+
+// MeiHuaHexagram 卦
+type MeiHuaHexagram struct {
+	Name        string `json:"name"`
+	Upper       string `json:"upper"`
+	Lower       string `json:"lower"`
+	UpperWuXing string `json:"upperWuXing"`
+	LowerWuXing string `json:"lowerWuXing"`
+	Lines       []bool `json:"lines"` // 自初爻起，true 为阳爻
+}
+
+// MeiHuaRelation 他卦对体卦的生克
+type MeiHuaRelation struct {
+	Gua      string `json:"gua"`     // 用卦、互卦上卦、互卦下卦、变卦
+	Trigram  string `json:"trigram"` // 经卦名
+	WuXing   string `json:"wuXing"`
+	Relation string `json:"relation"` // 比和、生体、体生、克体、体克
+	Verdict  string `json:"verdict"`
+}
+
+// MeiHuaResult 梅花易数卦盘
+type MeiHuaResult struct {
+	Method     string           `json:"method"` // 时间/数字
+	LunarDate  string           `json:"lunarDate,omitempty"`
+	Numbers    []int            `json:"numbers,omitempty"`
+	MovingLine int              `json:"movingLine"` // 动爻 1-6
+	Original   MeiHuaHexagram   `json:"original"`   // 本卦
+	Mutual     MeiHuaHexagram   `json:"mutual"`     // 互卦
+	Changed    MeiHuaHexagram   `json:"changed"`    // 变卦
+	Ti         string           `json:"ti"`         // 体卦
+	TiWuXing   string           `json:"tiWuXing"`
+	Yong       string           `json:"yong"` // 用卦
+	YongWuXing string           `json:"yongWuXing"`
+	Relation   string           `json:"relation"` // 体用生克，如 用生体
+	Verdict    string           `json:"verdict"`
+	Relations  []MeiHuaRelation `json:"relations"`
+	Error      string           `json:"error,omitempty"`
+}
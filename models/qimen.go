@@ -0,0 +1,39 @@
+package models
+
+// QiMenRequest 时家奇门排盘请求
+type QiMenRequest struct {
+	Date string `json:"date" binding:"required"` // YYYY-MM-DD
+	Time string `json:"time" binding:"required"` // HH:MM
+}
+
+// QiMenPalace 九宫之一
+type QiMenPalace struct {
+	Number      int      `json:"number"`      // 洛书宫数 1-9
+	Name        string   `json:"name"`        // 坎、坤……离
+	Direction   string   `json:"direction"`   // 方位
+	EarthStem   string   `json:"earthStem"`   // 地盘干
+	HeavenStems []string `json:"heavenStems"` // 天盘干（天禽所在宫有两干）
+	Stars       []string `json:"stars"`       // 九星（天禽随天芮）
+	Door        string   `json:"door,omitempty"`
+	Deity       string   `json:"deity,omitempty"` // 八神
+}
+
+// QiMenResult 时家奇门盘
+type QiMenResult struct {
+	Date    string        `json:"date"`
+	Time    string        `json:"time"`
+	Bazi    []string      `json:"bazi"`    // 年、月、日、时柱
+	JieQi   string        `json:"jieQi"`   // 节气
+	Yuan    string        `json:"yuan"`    // 上元/中元/下元
+	Dun     string        `json:"dun"`     // 阳遁/阴遁
+	Ju      int           `json:"ju"`      // 局数
+	JuName  string        `json:"juName"`  // 如 阴遁6局
+	XunShou string        `json:"xunShou"` // 时辰旬首及所遁之仪，如 甲子戊
+	ZhiFu   string        `json:"zhiFu"`   // 值符星
+	ZhiShi  string        `json:"zhiShi"`  // 值使门
+	XunKong []string      `json:"xunKong"` // 时辰旬空
+	MaXing  string        `json:"maXing"`  // 驿马
+	Palaces []QiMenPalace `json:"palaces"` // 按宫数 1-9 排列
+	Grid    [][]int       `json:"grid"`    // 九宫格布局（宫数，上南下北）
+	Error   string        `json:"error,omitempty"`
+}
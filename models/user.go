@@ -13,6 +13,8 @@ type User struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	IsActive  bool      `json:"is_active"`
+
+	SchoolProfile string `json:"school_profile,omitempty"` // 默认流派
 }
 
 type UserLogin struct {
@@ -26,6 +28,11 @@ type UserRegister struct {
 	Password string `json:"password" binding:"required,min=6"`
 }
 
+// SchoolProfileUpdate 设置默认流派请求
+type SchoolProfileUpdate struct {
+	School string `json:"school" binding:"required"`
+}
+
 type LoginResponse struct {
 	Token string `json:"token"`
 	User  User   `json:"user"`
@@ -0,0 +1,55 @@
+package models
+
+// ZiWeiRequest 紫微斗数排盘请求（农历生辰）
+type ZiWeiRequest struct {
+	Name        string `json:"name" binding:"required"`
+	LunarYear   int    `json:"lunarYear" binding:"required,min=1900,max=2100"`
+	LunarMonth  int    `json:"lunarMonth" binding:"required,min=1,max=12"`
+	LunarDay    int    `json:"lunarDay" binding:"required,min=1,max=30"`
+	IsLeapMonth bool   `json:"isLeapMonth,omitempty"` // 是否闰月
+	BirthTime   string `json:"birthTime" binding:"required"`
+	Gender      string `json:"gender" binding:"required,oneof=男 女"`
+}
+
+// ZiWeiStar 星曜
+type ZiWeiStar struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`            // 主星/吉星/煞星
+	SiHua string `json:"siHua,omitempty"` // 生年四化：化禄/化权/化科/化忌
+}
+
+// ZiWeiPalace 宫位
+type ZiWeiPalace struct {
+	Name        string      `json:"name"` // 命宫、兄弟……父母
+	Gan         string      `json:"gan"`
+	Zhi         string      `json:"zhi"`
+	ShenGong    bool        `json:"shenGong"` // 身宫所在
+	MainStars   []ZiWeiStar `json:"mainStars"`
+	Stars       []ZiWeiStar `json:"stars"`       // 辅星、煞星
+	DaXianStart int         `json:"daXianStart"` // 大限起始虚岁
+	DaXianEnd   int         `json:"daXianEnd"`
+}
+
+// ZiWeiSiHua 生年四化
+type ZiWeiSiHua struct {
+	Star   string `json:"star"`
+	Hua    string `json:"hua"`
+	Palace string `json:"palace"`
+}
+
+// ZiWeiResult 紫微斗数命盘
+type ZiWeiResult struct {
+	Name      string        `json:"name"`
+	Gender    string        `json:"gender"`
+	LunarDate string        `json:"lunarDate"` // 如 丙午年八月初八午时
+	YearGan   string        `json:"yearGan"`
+	YearZhi   string        `json:"yearZhi"`
+	MingGong  string        `json:"mingGong"` // 命宫干支
+	ShenGong  string        `json:"shenGong"` // 身宫所在宫名
+	WuXingJu  string        `json:"wuXingJu"` // 五行局
+	MingZhu   string        `json:"mingZhu"`  // 命主
+	ShenZhu   string        `json:"shenZhu"`  // 身主
+	SiHua     []ZiWeiSiHua  `json:"siHua"`
+	Palaces   []ZiWeiPalace `json:"palaces"` // 自命宫起逆行排列
+	Error     string        `json:"error,omitempty"`
+}
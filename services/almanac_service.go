@@ -0,0 +1,215 @@
+package services
+
+import (
+	"auspire/models"
+	"auspire/services/solarterm"
+	"fmt"
+	"time"
+)
+
+// AlmanacService 黄历服务
+//
+// 按日期给出当日年、月、日柱、所在节气、建除十二神、黄道黑道值神、二十八宿、
+// 冲煞、胎神占方、十二时辰吉凶及宜忌。干支沿用 BaziService 的排盘（月建以节气定），
+// 其余均由内置规则表推得。
+type AlmanacService struct {
+	baziService  *BaziService
+	naYinService *NaYinService
+}
+
+// almanacYiJi 建除十二神的宜忌事项
+type almanacYiJi struct {
+	yi []string
+	ji []string
+}
+
+// almanacXiu 二十八宿
+type almanacXiu struct {
+	name string // 星名，如 心月狐
+	luck string // 吉/凶
+}
+
+var (
+	// 建除十二神（日支与月建相同为建，依次顺排）
+	jianChuShen = []string{"建", "除", "满", "平", "定", "执", "破", "危", "成", "收", "开", "闭"}
+
+	// 建除十二神宜忌
+	jianChuYiJi = map[string]almanacYiJi{
+		"建": {[]string{"出行", "上任", "祈福", "会友"}, []string{"动土", "开仓", "嫁娶", "开市"}},
+		"除": {[]string{"祭祀", "沐浴", "扫舍", "求医", "搬家"}, []string{"嫁娶", "出行"}},
+		"满": {[]string{"祈福", "开市", "交易", "纳财", "立券"}, []string{"动土", "安葬", "上任", "搬家"}},
+		"平": {[]string{"修饰垣墙", "平治道涂", "祭祀"}, []string{"祈福", "开渠"}},
+		"定": {[]string{"嫁娶", "祭祀", "祈福", "立券", "开市", "纳畜"}, []string{"诉讼", "出行", "搬家"}},
+		"执": {[]string{"捕捉", "祭祀", "立券", "纳财"}, []string{"搬家", "出行", "开市", "开仓"}},
+		"破": {[]string{"求医", "破屋坏垣"}, []string{"嫁娶", "搬家", "开市", "立券", "出行", "动土"}},
+		"危": {[]string{"祭祀", "安床", "祈福"}, []string{"登高", "行船", "出行", "搬家"}},
+		"成": {[]string{"嫁娶", "开市", "入学", "立券", "搬家", "祈福"}, []string{"诉讼"}},
+		"收": {[]string{"纳财", "交易", "开市", "立券", "收割"}, []string{"出行", "安葬", "嫁娶"}},
+		"开": {[]string{"开市", "嫁娶", "入学", "出行", "搬家", "祈福"}, []string{"安葬", "破土", "立券"}},
+		"闭": {[]string{"安葬", "筑堤", "补垣"}, []string{"嫁娶", "搬家", "开市", "立券", "出行", "求医"}},
+	}
+
+	// 黄道黑道十二神（自青龙起顺排）
+	huangDaoShen = []string{"青龙", "明堂", "天刑", "朱雀", "金匮", "天德", "白虎", "玉堂", "天牢", "玄武", "司命", "勾陈"}
+
+	// 黄道六神
+	huangDaoJiShen = map[string]bool{"青龙": true, "明堂": true, "金匮": true, "天德": true, "玉堂": true, "司命": true}
+
+	// 青龙起处：寅申需加子，卯酉却在寅，辰戌龙位上，巳亥午中寻，子午临申地，丑未戌上存
+	// 日以月支起，时以日支起
+	qingLongStart = map[string]string{
+		"寅": "子", "申": "子", "卯": "寅", "酉": "寅", "辰": "辰", "戌": "辰",
+		"巳": "午", "亥": "午", "子": "申", "午": "申", "丑": "戌", "未": "戌",
+	}
+
+	// 二十八宿（按值日顺序，与七曜相配：角宿恒值木曜即星期四）
+	erShiBaXiu = []almanacXiu{
+		{"角木蛟", "吉"}, {"亢金龙", "凶"}, {"氐土貉", "凶"}, {"房日兔", "吉"}, {"心月狐", "凶"}, {"尾火虎", "吉"}, {"箕水豹", "吉"},
+		{"斗木獬", "吉"}, {"牛金牛", "凶"}, {"女土蝠", "凶"}, {"虚日鼠", "凶"}, {"危月燕", "凶"}, {"室火猪", "吉"}, {"壁水貐", "吉"},
+		{"奎木狼", "凶"}, {"娄金狗", "吉"}, {"胃土雉", "吉"}, {"昴日鸡", "凶"}, {"毕月乌", "吉"}, {"觜火猴", "凶"}, {"参水猿", "吉"},
+		{"井木犴", "吉"}, {"鬼金羊", "凶"}, {"柳土獐", "凶"}, {"星日马", "凶"}, {"张月鹿", "吉"}, {"翼火蛇", "凶"}, {"轸水蚓", "吉"},
+	}
+
+	// 胎神占方：日干定一处，日支定一处
+	taiShenGan = map[string]string{
+		"甲": "门", "己": "门", "乙": "碓磨", "庚": "碓磨", "丙": "厨灶",
+		"辛": "厨灶", "丁": "仓库", "壬": "仓库", "戊": "房床", "癸": "房床",
+	}
+	taiShenZhi = map[string]string{
+		"子": "碓", "午": "碓", "丑": "厕", "未": "厕", "寅": "炉", "申": "炉",
+		"卯": "门", "酉": "门", "辰": "栖", "戌": "栖", "巳": "床", "亥": "床",
+	}
+
+	// 岁煞方位：申子辰煞南，寅午戌煞北，巳酉丑煞东，亥卯未煞西
+	shaDirection = map[string]string{
+		"申子辰": "南", "寅午戌": "北", "巳酉丑": "东", "亥卯未": "西",
+	}
+
+	// 星期
+	weekdayNames = []string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+
+	// 二十八宿起算日：1900 年 1 月 1 日（星期一）
+	erShiBaXiuEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// 起算日值心月狐（二十八宿第 5 宿）
+const erShiBaXiuEpochIndex = 4
+
+func NewAlmanacService() *AlmanacService {
+	return &AlmanacService{
+		baziService:  NewBaziService(),
+		naYinService: NewNaYinService(),
+	}
+}
+
+// Daily 当日黄历
+//
+// 年、月、日柱取当日正午排盘（年以立春、月以节为界）；时辰取当日十二时辰，子时为早子时。
+func (s *AlmanacService) Daily(date time.Time) (*models.AlmanacResult, error) {
+	day := date.Format("2006-01-02")
+	bazi, err := s.baziService.calculateBaziColumns(day, "12:00")
+	if err != nil {
+		return &models.AlmanacResult{Date: day, Error: err.Error()}, err
+	}
+	yearColumn, monthColumn, dayColumn := bazi[0], bazi[1], bazi[2]
+
+	jianChu := jianChuOf(monthColumn.Zhi, dayColumn.Zhi)
+	god := huangDaoGod(monthColumn.Zhi, dayColumn.Zhi)
+	xiu := erShiBaXiu[((daysBetween(erShiBaXiuEpoch, date)+erShiBaXiuEpochIndex)%28+28)%28]
+	chongZhi := diZhiChong[dayColumn.Zhi]
+	chongGan := tianGan[(indexOf(tianGan, dayColumn.Gan)+4)%10]
+	jieQi, jieQiDate := currentTerm(date)
+
+	result := &models.AlmanacResult{
+		Date:      day,
+		Weekday:   weekdayNames[date.Weekday()],
+		Year:      yearColumn.Gan + yearColumn.Zhi,
+		Month:     monthColumn.Gan + monthColumn.Zhi,
+		Day:       dayColumn.Gan + dayColumn.Zhi,
+		ShengXiao: shengXiao[yearColumn.Zhi],
+		NaYin:     s.naYinService.Calculate(dayColumn.Gan, dayColumn.Zhi),
+		JieQi:     jieQi,
+		JieQiDate: jieQiDate.Format("2006-01-02"),
+		JianChu:   jianChu,
+		God:       god,
+		HuangDao:  huangDaoJiShen[god],
+		Xiu:       xiu.name,
+		XiuLuck:   xiu.luck,
+		Chong:     fmt.Sprintf("冲%s(%s%s)", shengXiao[chongZhi], chongGan, chongZhi),
+		Sha:       "煞" + shaDirection[diZhiSanHe[dayColumn.Zhi]],
+		TaiShen:   "占" + taiShenGan[dayColumn.Gan] + taiShenZhi[dayColumn.Zhi],
+		Hours:     []models.AlmanacHour{},
+		JiShi:     []string{},
+		Yi:        jianChuYiJi[jianChu].yi,
+		Ji:        jianChuYiJi[jianChu].ji,
+	}
+
+	// 日支冲年支为岁破，诸事不宜
+	if isDiZhiChong(dayColumn.Zhi, yearColumn.Zhi) {
+		result.SuiPo = true
+		result.Yi = []string{}
+		result.Ji = []string{"诸事不宜"}
+	}
+
+	for i := range diZhi {
+		column := s.baziService.calculateHourColumn(dayColumn, i*2)
+		hourGod := huangDaoGod(dayColumn.Zhi, column.Zhi)
+		hour := models.AlmanacHour{
+			Zhi:        column.Zhi,
+			GanZhi:     column.Gan + column.Zhi,
+			Time:       shiChenRange(i),
+			God:        hourGod,
+			HuangDao:   huangDaoJiShen[hourGod],
+			Chong:      "冲" + shengXiao[diZhiChong[column.Zhi]],
+			Auspicious: huangDaoJiShen[hourGod] && !isDiZhiChong(column.Zhi, dayColumn.Zhi),
+		}
+		if hour.Auspicious {
+			result.JiShi = append(result.JiShi, column.Zhi)
+		}
+		result.Hours = append(result.Hours, hour)
+	}
+
+	return result, nil
+}
+
+// currentTerm 当日所在的节气（节或中气，取较近者）及其交节日期
+func currentTerm(date time.Time) (string, time.Time) {
+	jie, jieDate := solarterm.PrevJie(date)
+	zhongQi, zhongQiDate := solarterm.PrevZhongQi(date)
+	if zhongQiDate.After(jieDate) {
+		return zhongQi, zhongQiDate
+	}
+	return jie, jieDate
+}
+
+// jianChuOf 建除十二神：日支与月建相同为建，依次顺排
+func jianChuOf(monthZhi, dayZhi string) string {
+	return jianChuShen[(indexOf(diZhi, dayZhi)-indexOf(diZhi, monthZhi)+12)%12]
+}
+
+// jianChuVerdict 建除十二神对某事项的宜忌：1 宜，-1 忌，0 平
+func jianChuVerdict(jianChu, activity string) int {
+	yiJi := jianChuYiJi[jianChu]
+	switch {
+	case indexOf(yiJi.yi, activity) != -1:
+		return 1
+	case indexOf(yiJi.ji, activity) != -1:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// huangDaoGod 黄道黑道值神：日以月支起青龙，时以日支起青龙
+func huangDaoGod(base, zhi string) string {
+	start := indexOf(diZhi, qingLongStart[base])
+	return huangDaoShen[(indexOf(diZhi, zhi)-start+12)%12]
+}
+
+// shiChenRange 时辰对应的钟点，子时取早子时
+func shiChenRange(index int) string {
+	if index == 0 {
+		return "00:00-00:59"
+	}
+	return fmt.Sprintf("%02d:00-%02d:59", index*2-1, index*2)
+}
@@ -4,12 +4,17 @@ import (
 	"auspire/models"
 	"auspire/services/solarterm"
 	"fmt"
+	"strings"
 	"time"
+	"unicode"
 )
 
 var (
 	tianGan = []string{"甲", "乙", "丙", "丁", "戊", "己", "庚", "辛", "壬", "癸"}
 	diZhi   = []string{"子", "丑", "寅", "卯", "辰", "巳", "午", "未", "申", "酉", "戌", "亥"}
+
+	// 五行（按相生顺序）
+	wuXingList = []string{"木", "火", "土", "金", "水"}
 	
 	// 天干对应的五行
 	tianGanWuXing = map[string]string{
@@ -23,15 +28,6 @@ var (
 		"午": "火", "未": "土", "申": "金", "酉": "金", "戌": "土", "亥": "水",
 	}
 	
-	// 十二长生：五行在十二地支中的长生状态
-	shiErChangSheng = map[string]map[string]string{
-		"木": {"亥": "长生", "子": "沐浴", "丑": "冠带", "寅": "临官", "卯": "帝旺", "辰": "衰", "巳": "病", "午": "死", "未": "墓", "申": "绝", "酉": "胎", "戌": "养"},
-		"火": {"寅": "长生", "卯": "沐浴", "辰": "冠带", "巳": "临官", "午": "帝旺", "未": "衰", "申": "病", "酉": "死", "戌": "墓", "亥": "绝", "子": "胎", "丑": "养"},
-		"土": {"寅": "长生", "卯": "沐浴", "辰": "冠带", "巳": "临官", "午": "帝旺", "未": "衰", "申": "病", "酉": "死", "戌": "墓", "亥": "绝", "子": "胎", "丑": "养"},
-		"金": {"巳": "长生", "午": "沐浴", "未": "冠带", "申": "临官", "酉": "帝旺", "戌": "衰", "亥": "病", "子": "死", "丑": "墓", "寅": "绝", "卯": "胎", "辰": "养"},
-		"水": {"申": "长生", "酉": "沐浴", "戌": "冠带", "亥": "临官", "子": "帝旺", "丑": "衰", "寅": "病", "卯": "死", "辰": "墓", "巳": "绝", "午": "胎", "未": "养"},
-	}
-
 	// 节气与地支的对应关系（月支由节气决定）
 	// 立春(2/4) 开始为寅月，惊蛰(3/6) 开始为卯月，清明(4/5) 开始为辰月
 	// 立夏(5/6) 开始为巳月，芒种(6/6) 开始为午月，小暑(7/7) 开始为未月
@@ -55,30 +51,49 @@ var (
 )
 
 type BaziService struct{
+	profile         *SchoolProfile
 	zhuXingService  *ZhuXingService
-	cangGanService  *CangGanService
 	fuXingService   *FuXingService
 	naYinService    *NaYinService
 	xingYunService  *XingYunService
 	ziZuoService    *ZiZuoService
 	kongWangService *KongWangService
 	shenShaService  *ShenShaService
+	daYunService    *DaYunService
+	liuQinService   *LiuQinService
 }
 
 func NewBaziService() *BaziService {
+	return newBaziService(defaultSchoolProfile())
+}
+
+// newBaziService 按流派创建八字服务，藏干、星运、自坐、神煞均使用该流派的数据表
+func newBaziService(profile *SchoolProfile) *BaziService {
 	return &BaziService{
+		profile:         profile,
 		zhuXingService:  NewZhuXingService(),
-		cangGanService:  NewCangGanService(),
-		fuXingService:   NewFuXingService(),
+		fuXingService:   newFuXingService(profile),
 		naYinService:    NewNaYinService(),
-		xingYunService:  NewXingYunService(),
-		ziZuoService:    NewZiZuoService(),
+		xingYunService:  newXingYunService(profile),
+		ziZuoService:    newZiZuoService(profile),
 		kongWangService: NewKongWangService(),
-		shenShaService:  NewShenShaService(),
+		shenShaService:  newShenShaService(profile),
+		daYunService:    NewDaYunService(),
+		liuQinService:   NewLiuQinService(),
+	}
+}
+
+// withProfile 返回绑定指定流派的服务实例，流派相同时返回自身
+func (s *BaziService) withProfile(profile *SchoolProfile) *BaziService {
+	if profile == s.profile {
+		return s
 	}
+	return newBaziService(profile)
 }
 
-func (s *BaziService) CalculateBazi(req models.BaziRequest) (*models.BaziResponse, error) {
+func (s *BaziService) CalculateBazi(req models.BaziRequest, opts CalcOptions) (*models.BaziResponse, error) {
+	s = s.withProfile(opts.profile())
+
 	bazi, err := s.calculateBaziColumns(req.BirthDate, req.BirthTime)
 	if err != nil {
 		return &models.BaziResponse{
@@ -91,15 +106,137 @@ func (s *BaziService) CalculateBazi(req models.BaziRequest) (*models.BaziRespons
 	shiErChangShengResult := s.calculateShiErChangSheng(bazi)
 
 	// 增强计算：添加新功能
-	bazi = s.enhanceBaziColumns(bazi)
+	trace := newTraceRecorder(opts)
+	bazi = s.enhanceBaziColumns(bazi, trace)
+
+	// 大运需性别定顺逆、六亲按性别取用，流年自请求指定之年起排十年
+	birth, _ := time.Parse("2006-01-02 15:04", req.BirthDate+" "+req.BirthTime)
+	var daYun *models.DaYunInfo
+	var liuQin []models.LiuQinEntry
+	if req.Gender != "" {
+		daYun = s.daYunService.CalculateDaYun(bazi, birth, req.Gender)
+		liuQin = s.calculateLiuQin(bazi, req.Gender)
+	}
+	var liuNian []models.LiuNianPillar
+	if req.LiuNianYear != 0 {
+		liuNian = s.daYunService.CalculateLiuNian(bazi, req.LiuNianYear, birth.Year())
+	}
+
+	return &models.BaziResponse{
+		Name:            req.Name,
+		Bazi:            bazi,
+		ShiErChangSheng: shiErChangShengResult,
+		NaYin:           s.naYinService.Analyze(bazi),
+		KongWang:        s.kongWangService.Analyze(bazi),
+		LiuQin:          liuQin,
+		DaYun:           daYun,
+		LiuNian:         liuNian,
+		School:          s.profile.ID(),
+		ChangShengMode:  string(s.profile.ChangShengMode),
+		Trace:           trace.result(),
+	}, nil
+}
+
+// CalculateFromPillars 根据手工录入的四柱干支排盘（无需出生时间）
+//
+// 适用于分析古籍或其他排盘软件中的命例：校验八字为合法干支后，
+// 执行与出生时间排盘相同的十二长生及增强计算。
+func (s *BaziService) CalculateFromPillars(req models.PillarsRequest, opts CalcOptions) (*models.BaziResponse, error) {
+	s = s.withProfile(opts.profile())
+
+	bazi, err := s.parsePillars(req.Pillars)
+	if err != nil {
+		return &models.BaziResponse{
+			Name:  req.Name,
+			Error: err.Error(),
+		}, err
+	}
+
+	shiErChangShengResult := s.calculateShiErChangSheng(bazi)
+
+	trace := newTraceRecorder(opts)
+	bazi = s.enhanceBaziColumns(bazi, trace)
+
+	var liuQin []models.LiuQinEntry
+	if req.Gender != "" {
+		liuQin = s.calculateLiuQin(bazi, req.Gender)
+	}
+	var liuNian []models.LiuNianPillar
+	if req.LiuNianYear != 0 {
+		liuNian = s.daYunService.CalculateLiuNian(bazi, req.LiuNianYear, 0)
+	}
 
 	return &models.BaziResponse{
 		Name:            req.Name,
 		Bazi:            bazi,
 		ShiErChangSheng: shiErChangShengResult,
+		NaYin:           s.naYinService.Analyze(bazi),
+		KongWang:        s.kongWangService.Analyze(bazi),
+		LiuQin:          liuQin,
+		LiuNian:         liuNian,
+		School:          s.profile.ID(),
+		ChangShengMode:  string(s.profile.ChangShengMode),
+		Trace:           trace.result(),
 	}, nil
 }
 
+// parsePillars 解析"庚午 甲申 癸未 丁巳"形式的四柱，分隔符可省略
+func (s *BaziService) parsePillars(text string) ([]models.BaziColumn, error) {
+	chars := []string{}
+	for _, r := range text {
+		if unicode.IsSpace(r) || r == ',' || r == '，' || r == '、' {
+			continue
+		}
+		chars = append(chars, string(r))
+	}
+
+	if len(chars) != 8 {
+		return nil, fmt.Errorf("四柱须为8个干支字，实际为%d个", len(chars))
+	}
+
+	columnNames := []string{"年柱", "月柱", "日柱", "时柱"}
+	bazi := make([]models.BaziColumn, 0, 4)
+	for i := 0; i < 4; i++ {
+		gan, zhi := chars[i*2], chars[i*2+1]
+		ganIndex := indexOf(tianGan, gan)
+		zhiIndex := indexOf(diZhi, zhi)
+		if ganIndex == -1 {
+			return nil, fmt.Errorf("%s天干无效: %s", columnNames[i], gan)
+		}
+		if zhiIndex == -1 {
+			return nil, fmt.Errorf("%s地支无效: %s", columnNames[i], zhi)
+		}
+		// 六十甲子中阳干配阳支、阴干配阴支
+		if ganIndex%2 != zhiIndex%2 {
+			return nil, fmt.Errorf("%s%s%s不是合法的干支组合（阴阳不配）", columnNames[i], gan, zhi)
+		}
+
+		bazi = append(bazi, models.BaziColumn{
+			Gan:       gan,
+			Zhi:       zhi,
+			GanWuXing: tianGanWuXing[gan],
+			ZhiWuXing: diZhiWuXing[zhi],
+		})
+	}
+
+	return bazi, nil
+}
+
+// rebuildColumns 只取四柱干支重新排盘，按流派重算主星、藏干、星运、自坐、空亡、神煞等，
+// 不采信调用方传入的派生字段
+func (s *BaziService) rebuildColumns(bazi []models.BaziColumn) []models.BaziColumn {
+	columns := make([]models.BaziColumn, len(bazi))
+	for i, column := range bazi {
+		columns[i] = models.BaziColumn{
+			Gan:       column.Gan,
+			Zhi:       column.Zhi,
+			GanWuXing: tianGanWuXing[column.Gan],
+			ZhiWuXing: diZhiWuXing[column.Zhi],
+		}
+	}
+	return s.enhanceBaziColumns(columns, nil)
+}
+
 func (s *BaziService) calculateBaziColumns(birthDate, birthTime string) ([]models.BaziColumn, error) {
 	parsedDate, err := time.Parse("2006-01-02", birthDate)
 	if err != nil {
@@ -111,14 +248,28 @@ func (s *BaziService) calculateBaziColumns(birthDate, birthTime string) ([]model
 		return nil, fmt.Errorf("时间格式错误: %v", err)
 	}
 
-	year := parsedDate.Year()
-	// month := int(parsedDate.Month())  // 删除未使用的变量
 	hour := parsedTime.Hour()
 
+	// 23 点后的子时按流派处理：子初换日的流派日柱、时柱均换到次日，早晚子时的流派
+	// 日柱仍取当日、时干按次日起（夜子时），子正换日的流派日柱、时柱均按当日
+	dayDate := parsedDate
+	hourDate := parsedDate
+	if hour == 23 {
+		switch s.profile.ZiShiMode {
+		case ZiShiZiChuHuanRi:
+			dayDate = parsedDate.AddDate(0, 0, 1)
+			hourDate = dayDate
+		case ZiShiZaoWanZi:
+			hourDate = parsedDate.AddDate(0, 0, 1)
+		}
+	}
+
+	// 年柱以立春为岁首
+	year := solarterm.GetBaziYear(dayDate)
 	yearColumn := s.calculateYearColumn(year)
-	monthColumn := s.calculateMonthColumn(parsedDate)
-	dayColumn := s.calculateDayColumn(parsedDate)
-	hourColumn := s.calculateHourColumn(dayColumn, hour)
+	monthColumn := s.calculateMonthColumn(dayDate)
+	dayColumn := s.calculateDayColumn(dayDate)
+	hourColumn := s.calculateHourColumn(s.calculateDayColumn(hourDate), hour)
 
 	return []models.BaziColumn{
 		yearColumn,
@@ -168,26 +319,16 @@ func (s *BaziService) calculateYearColumn(year int) models.BaziColumn {
 // 11. 子月：大雪(12/7) - 冬至(12/22) → 对应地支"子"
 // 12. 丑月：小寒(1/6) - 大寒(1/20) → 对应地支"丑"
 func (s *BaziService) calculateMonthColumn(date time.Time) models.BaziColumn {
-	year := date.Year()
+	// 月干按立春起算的年干推五虎遁
+	year := solarterm.GetBaziYear(date)
 	yearGanIndex := (year - 4) % 10
 	if yearGanIndex < 0 {
 		yearGanIndex += 10
 	}
 	
-	// 根据日期获取节气对应的月支
+	// 根据日期前最近的"节"获取月支
 	monthZhi := solarterm.GetMonthDiZhi(date)
 	
-	// 如果无法通过节气获取月支，则使用默认方法（仅用于测试或fallback）
-	if monthZhi == "" {
-		// 获取月份对应的地支索引
-		// 这里使用简化的计算方式，实际应该根据节气精确计算
-		month := int(date.Month())
-		// 简化处理：以立春(通常在2月4日左右)为寅月起点
-		// 这里需要根据具体年份的节气日期精确计算
-		monthZhiIndex := (month + 1) % 12 // 简化处理
-		monthZhi = diZhi[monthZhiIndex]
-	}
-	
 	// 根据年干和月支计算月干
 	// 使用五虎遁诀计算月干
 	monthGan := s.calculateMonthGan(yearGanIndex, monthZhi)
@@ -226,12 +367,9 @@ func (s *BaziService) calculateMonthGan(yearGanIndex int, monthZhi string) strin
 	}
 	
 	// 计算月干索引
-	// 从寅月(2)开始计算，所以需要减去2
+	// 从寅月(2)开始计算，子、丑两月为岁末第十一、十二月
 	startGanIndex := yueGanStartMap[yearGanIndex]
-	monthGanIndex := (startGanIndex + monthZhiIndex - 2) % 10
-	if monthGanIndex < 0 {
-		monthGanIndex += 10
-	}
+	monthGanIndex := (startGanIndex + (monthZhiIndex-2+12)%12) % 10
 	
 	return tianGan[monthGanIndex]
 }
@@ -257,7 +395,7 @@ func (s *BaziService) calculateDayColumn(date time.Time) models.BaziColumn {
 }
 
 func (s *BaziService) calculateHourColumn(dayColumn models.BaziColumn, hour int) models.BaziColumn {
-	dayGanIndex := s.findGanIndex(dayColumn.Gan)
+	dayGanIndex := indexOf(tianGan, dayColumn.Gan)
 	hourZhiIndex := ((hour + 1) / 2) % 12
 	hourGanIndex := (dayGanIndex*2 + hourZhiIndex) % 10
 
@@ -269,13 +407,14 @@ func (s *BaziService) calculateHourColumn(dayColumn models.BaziColumn, hour int)
 	}
 }
 
-func (s *BaziService) findGanIndex(gan string) int {
-	for i, g := range tianGan {
-		if g == gan {
+// indexOf 返回字符在干支序列中的位置，不存在时返回-1
+func indexOf(list []string, target string) int {
+	for i, item := range list {
+		if item == target {
 			return i
 		}
 	}
-	return 0
+	return -1
 }
 
 // 计算十二长生图
@@ -284,53 +423,86 @@ func (s *BaziService) calculateShiErChangSheng(bazi []models.BaziColumn) map[str
 	
 	// 以日干（日主）为准计算十二长生
 	dayColumn := bazi[2] // 日柱
-	dayGanWuXing := dayColumn.GanWuXing
 	
-	// 计算各柱地支在日干五行下的长生状态
+	// 计算各柱地支在日干下的长生状态（取法及土的寄生随流派）
 	for i, column := range bazi {
 		columnName := []string{"年", "月", "日", "时"}[i]
-		if changShengMap, exists := shiErChangSheng[dayGanWuXing]; exists {
-			if changSheng, exists := changShengMap[column.Zhi]; exists {
-				result[columnName+"支"] = changSheng
-			}
+		if changSheng := s.xingYunService.Calculate(dayColumn.Gan, column.Zhi); changSheng != "" {
+			result[columnName+"支"] = changSheng
 		}
 	}
 	
 	return result
 }
 
+// calculateLiuQin 按性别标注各柱天干、藏干的六亲并汇总主要亲属
+func (s *BaziService) calculateLiuQin(bazi []models.BaziColumn, gender string) []models.LiuQinEntry {
+	s.liuQinService.Annotate(bazi, gender)
+	return s.liuQinService.Summarize(bazi, gender)
+}
+
 // 增强八字柱子计算，添加主星、藏干、副星、纳音等
-func (s *BaziService) enhanceBaziColumns(bazi []models.BaziColumn) []models.BaziColumn {
+func (s *BaziService) enhanceBaziColumns(bazi []models.BaziColumn, trace *traceRecorder) []models.BaziColumn {
 	dayColumn := bazi[2] // 日柱作为主星参考
 	dayGan := dayColumn.Gan
+	columnNames := []string{"年柱", "月柱", "日柱", "时柱"}
 
 	for i := range bazi {
-		// 计算藏干
-		bazi[i].CangGan = s.cangGanService.Calculate(bazi[i].Zhi)
+		pillar := columnNames[i]
 
 		// 计算主星（以日干为准计算每柱的关系）
 		bazi[i].ZhuXing = s.zhuXingService.Calculate(dayGan, bazi[i].Gan)
-
-		// 计算副星（十神关系）
-		bazi[i].FuXing = s.fuXingService.Calculate(dayGan, bazi[i])
-
-		// 计算纳音（年柱）
-		if i == 0 { // 年柱
-			bazi[i].NaYin = s.naYinService.Calculate(bazi[i].Gan, bazi[i].Zhi)
+		trace.record("zhuXing", pillar, "十神：以日干为我，论天干五行生克与阴阳",
+			map[string]string{"日干": dayGan, "天干": bazi[i].Gan},
+			s.zhuXingService.Explain(dayGan, bazi[i].Gan), bazi[i].ZhuXing)
+
+		// 计算藏干及副星（藏干对日干的十神）
+		bazi[i].CangGan = s.fuXingService.Calculate(dayGan, bazi[i])
+		for _, item := range bazi[i].CangGan {
+			trace.record("fuXing", pillar, "副星：地支藏干对日干的十神，按本气、中气、余气计力量占比",
+				map[string]string{"日干": dayGan, "地支": bazi[i].Zhi, "藏干": item.Gan},
+				fmt.Sprintf("cangGan[%s] %s=%s（%d%%）；%s", bazi[i].Zhi, item.Gan, item.Role, item.Weight, s.zhuXingService.Explain(dayGan, item.Gan)),
+				item.ShiShen)
 		}
 
+		// 计算纳音（四柱均取）
+		bazi[i].NaYin = s.naYinService.Calculate(bazi[i].Gan, bazi[i].Zhi)
+		trace.record("naYin", pillar, "纳音：以本柱干支查六十甲子纳音",
+			map[string]string{"天干": bazi[i].Gan, "地支": bazi[i].Zhi},
+			fmt.Sprintf("naYinData[%s%s]", bazi[i].Gan, bazi[i].Zhi), bazi[i].NaYin)
+
 		// 计算星运（十二运程）
-		bazi[i].XingYun = s.xingYunService.Calculate(dayColumn.GanWuXing, bazi[i].Zhi)
+		bazi[i].XingYun = s.xingYunService.Calculate(dayGan, bazi[i].Zhi)
+		trace.record("xingYun", pillar, "星运：日干在本柱地支的十二长生",
+			map[string]string{"日干": dayGan, "地支": bazi[i].Zhi},
+			fmt.Sprintf("十二长生(%s)[%s][%s]（%s）", s.profile.changShengRule(), dayGan, bazi[i].Zhi, s.profile.ID()), bazi[i].XingYun)
 
 		// 计算自坐（天干在地支的状态）
 		bazi[i].ZiZuo = s.ziZuoService.Calculate(bazi[i].Gan, bazi[i].Zhi)
+		trace.record("ziZuo", pillar, "自坐：本柱天干坐下地支，藏干见本干为本气，否则论十二长生",
+			map[string]string{"天干": bazi[i].Gan, "地支": bazi[i].Zhi},
+			s.ziZuoService.Explain(bazi[i].Gan, bazi[i].Zhi), bazi[i].ZiZuo)
 
 		// 计算空亡
 		bazi[i].KongWang = s.kongWangService.Calculate(dayColumn.Gan+dayColumn.Zhi, bazi[i].Zhi)
+		trace.record("kongWang", pillar, "空亡：以日柱所在旬查旬空地支",
+			map[string]string{"日柱": dayColumn.Gan + dayColumn.Zhi, "地支": bazi[i].Zhi},
+			fmt.Sprintf("kongWangData[%s]=%v", dayColumn.Gan+dayColumn.Zhi, s.kongWangService.GetKongWangZhi(dayColumn.Gan+dayColumn.Zhi)),
+			fmt.Sprintf("%t", bazi[i].KongWang))
+		bazi[i].NianKong = s.kongWangService.Calculate(bazi[0].Gan+bazi[0].Zhi, bazi[i].Zhi)
+		trace.record("kongWang", pillar, "年空：以年柱所在旬查旬空地支",
+			map[string]string{"年柱": bazi[0].Gan + bazi[0].Zhi, "地支": bazi[i].Zhi},
+			fmt.Sprintf("kongWangData[%s]=%v", bazi[0].Gan+bazi[0].Zhi, s.kongWangService.GetKongWangZhi(bazi[0].Gan+bazi[0].Zhi)),
+			fmt.Sprintf("%t", bazi[i].NianKong))
 
 		// 计算神煞
-		bazi[i].ShenSha = s.shenShaService.CalculateForColumn(dayGan, bazi[i])
+		bazi[i].ShenSha = s.shenShaService.CalculateForColumn(bazi, i)
+		for _, m := range s.shenShaService.matchColumn(bazi, i) {
+			trace.record("shenSha", pillar, fmt.Sprintf("神煞：%s以%s查%s（%s）", m.Rule.Name, m.Basis, m.Rule.Target, strings.Join(m.Rule.Sources, "、")),
+				map[string]string{string(m.Basis): m.Key, "天干": bazi[i].Gan, "地支": bazi[i].Zhi},
+				fmt.Sprintf("%s[%s]=%v", m.Rule.Name, m.Key, m.Rule.Table[m.Key]), m.Rule.Name)
+		}
 	}
 
 	return bazi
-}
\ No newline at end of file
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"auspire/models"
+	"strings"
+	"testing"
+)
+
+// pillarsOf 排盘并返回年、月、日、时四柱干支
+func pillarsOf(t *testing.T, s *BaziService, date, clock string) []string {
+	t.Helper()
+	bazi, err := s.calculateBaziColumns(date, clock)
+	if err != nil {
+		t.Fatalf("calculateBaziColumns(%s %s) error: %v", date, clock, err)
+	}
+	pillars := make([]string, len(bazi))
+	for i, column := range bazi {
+		pillars[i] = column.Gan + column.Zhi
+	}
+	return pillars
+}
+
+func TestCalculateBaziColumnsCalendar(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		// 立春（2024-02-04）前属癸卯年，丑月为岁末第十二月
+		{"2024-01-15", "癸卯 乙丑 戊寅 戊午"},
+		{"2024-02-03", "癸卯 乙丑 丁酉 丙午"},
+		{"2024-02-04", "甲辰 丙寅 戊戌 戊午"},
+		// 惊蛰（2024-03-05）当日即入卯月
+		{"2024-03-04", "甲辰 丙寅 丁卯 丙午"},
+		{"2024-03-05", "甲辰 丁卯 戊辰 戊午"},
+		// 芒种后属午月
+		{"2024-06-15", "甲辰 庚午 庚戌 壬午"},
+		// 子月为岁末第十一月
+		{"2024-12-15", "甲辰 丙子 癸丑 戊午"},
+		// 小寒（2025-01-05）入丑月，仍属甲辰年
+		{"2025-01-04", "甲辰 丙子 癸酉 戊午"},
+		{"2025-01-05", "甲辰 丁丑 甲戌 庚午"},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(pillarsOf(t, NewBaziService(), tt.date, "12:00"), " "); got != tt.want {
+			t.Errorf("%s 12:00 = %s, want %s", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateBaziColumnsZiShi(t *testing.T) {
+	tests := []struct {
+		school string
+		clock  string
+		want   string
+	}{
+		// 默认流派子正换日：23 点仍按当日，与引入流派配置前一致
+		{"legacy", "22:30", "庚戌 丁亥"},
+		{"legacy", "23:30", "庚戌 丙子"},
+		{"legacy", "00:30", "庚戌 丙子"},
+		// 子初换日：日柱、时柱均取次日辛亥日
+		{"traditional", "23:30", "辛亥 戊子"},
+		{"traditional", "00:30", "庚戌 丙子"},
+		// 早晚子时：日柱不换，时干按次日起
+		{"modern", "23:30", "庚戌 戊子"},
+		{"modern", "00:30", "庚戌 丙子"},
+	}
+
+	for _, tt := range tests {
+		profile, err := GetSchoolProfile(tt.school)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 只比较日柱、时柱
+		pillars := pillarsOf(t, newBaziService(profile), "2024-06-15", tt.clock)
+		if got := strings.Join(pillars[2:], " "); got != tt.want {
+			t.Errorf("%s 2024-06-15 %s = %s, want %s", tt.school, tt.clock, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultSchoolProfile(t *testing.T) {
+	if profile := defaultSchoolProfile(); profile.Name != "legacy" || profile.ZiShiMode != ZiShiZiZhengHuanRi {
+		t.Errorf("default profile = %s (%s), want legacy (%s)", profile.Name, profile.ZiShiMode, ZiShiZiZhengHuanRi)
+	}
+}
+
+func TestChangShengBySchool(t *testing.T) {
+	tests := []struct {
+		school  string
+		xingYun []string
+		ziZuo   []string
+	}{
+		// 默认流派五行同生同死、土随火，与引入流派配置前的星运、自坐一致
+		{"legacy", []string{"胎", "长生", "养", "绝"}, []string{"自坐沐浴", "自坐绝", "自坐养", "自坐临官"}},
+		// 阳生阴死：癸长生于卯逆行，丁长生于酉逆行
+		{"traditional", []string{"绝", "死", "墓", "胎"}, []string{"自坐沐浴", "自坐绝", "自坐墓", "自坐帝旺"}},
+	}
+
+	for _, tt := range tests {
+		profile, err := GetSchoolProfile(tt.school)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := NewBaziService().CalculateFromPillars(models.PillarsRequest{Name: "test", Pillars: "庚午 甲申 癸未 丁巳"}, CalcOptions{Profile: profile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, column := range result.Bazi {
+			if column.XingYun != tt.xingYun[i] || column.ZiZuo != tt.ziZuo[i] {
+				t.Errorf("%s pillar %d: xingYun=%s ziZuo=%s, want %s %s", tt.school, i, column.XingYun, column.ZiZuo, tt.xingYun[i], tt.ziZuo[i])
+			}
+			if got := result.ShiErChangSheng[[]string{"年", "月", "日", "时"}[i]+"支"]; got != tt.xingYun[i] {
+				t.Errorf("%s shiErChangSheng pillar %d = %s, want %s", tt.school, i, got, tt.xingYun[i])
+			}
+		}
+	}
+}
@@ -19,6 +19,7 @@ package services
 import (
 	"auspire/models"
 	"fmt"
+	"strings"
 )
 
 // BaziyuceService 四柱八字综合分析服务
@@ -29,14 +30,21 @@ import (
 // 2. Vitality assessment (定旺衰，识体性)  
 // 3. Favorable elements identification (明喜忌，定方向)
 // 4. Pattern analysis (析格局，观组合)
-// 5. Luck period forecasting (推大运，断流年)
+// 5. Palace analysis (看宫位，论六亲)
+// 6. Luck period forecasting (推大运，断流年)
 //
 // The analysis follows classical texts and methodologies including:
 // - "子平真诠" (Zi Ping Zhen Quan) principles
 // - "穷通宝鉴" (Qiong Tong Bao Jian) seasonal analysis
 // - "三命通会" (San Ming Tong Hui) comprehensive approaches
 type BaziyuceService struct {
-	// Future enhancements could include caching, external API clients, etc.
+	profile        *SchoolProfile
+	baziService    *BaziService
+	tiaoHouService *TiaoHouService
+	graphService   *ChartGraphService
+	naYinService   *NaYinService
+	palaceService  *PalaceService
+	shiShenService *ShiShenService
 }
 
 // NewBaziyuceService 创建新的四柱八字综合分析服务实例
@@ -44,13 +52,35 @@ type BaziyuceService struct {
 // Returns a pointer to a newly initialized BaziyuceService.
 // This follows the singleton pattern commonly used in Go services.
 func NewBaziyuceService() *BaziyuceService {
-	return &BaziyuceService{}
+	return newBaziyuceService(defaultSchoolProfile())
+}
+
+// newBaziyuceService creates an analysis service bound to a school profile
+// (hidden stems for root detection, 十二长生 for 得令).
+func newBaziyuceService(profile *SchoolProfile) *BaziyuceService {
+	return &BaziyuceService{
+		profile:        profile,
+		baziService:    newBaziService(profile),
+		tiaoHouService: newTiaoHouService(profile),
+		graphService:   newChartGraphService(profile),
+		naYinService:   NewNaYinService(),
+		palaceService:  NewPalaceService(),
+		shiShenService: newShiShenService(profile),
+	}
+}
+
+// withProfile returns a service bound to the given profile (itself if unchanged).
+func (s *BaziyuceService) withProfile(profile *SchoolProfile) *BaziyuceService {
+	if profile == s.profile {
+		return s
+	}
+	return newBaziyuceService(profile)
 }
 
 // Analyze 四柱八字综合分析入口点
 //
 // This is the main entry point for comprehensive BaZi analysis.
-// It orchestrates all five major analytical steps in traditional order:
+// It orchestrates all six major analytical steps in traditional order:
 //
 // Step 1: Chart Establishment (排盘与定盘)
 //   - Verifies the accuracy of the four pillars
@@ -73,21 +103,30 @@ func NewBaziyuceService() *BaziyuceService {
 //   - Analyzes noble person stars and other divine influences
 //   - Considers palace positions and relationships
 //
-// Step 5: Luck Period Forecasting (推大运，断流年)
+// Step 5: Palace Analysis (看宫位，论六亲)
+//   - Evaluates each pillar's palace: ten gods, 十二长生, shensha, 空亡, clashes
+//
+// Step 6: Luck Period Forecasting (推大运，断流年)
 //   - Projects future luck periods
 //   - Analyzes annual influences
 //   - Provides temporal guidance
 //
 // Parameters:
 //   - bazi: Slice of BaziColumn representing the four pillars
+//   - opts: Per-request options (Explain for the provenance trace, Profile for the school)
 //
 // Returns:
 //   - Pointer to BaziyuceResult containing all analysis steps
-//   - Following the traditional five-step analysis methodology
-func (s *BaziyuceService) Analyze(bazi []models.BaziColumn) *models.BaziyuceResult {
+//   - Following the traditional six-step analysis methodology
+func (s *BaziyuceService) Analyze(bazi []models.BaziColumn, opts CalcOptions) *models.BaziyuceResult {
+	s = s.withProfile(opts.profile())
+
 	result := &models.BaziyuceResult{
-		Steps: []models.AnalysisStep{},
+		Steps:          []models.AnalysisStep{},
+		School:         s.profile.ID(),
+		ChangShengMode: string(s.profile.ChangShengMode),
 	}
+	trace := newTraceRecorder(opts)
 
 	// 第一步：排盘与定盘 - Chart Establishment
 	// Establishes the foundational chart and confirms accuracy
@@ -96,7 +135,7 @@ func (s *BaziyuceService) Analyze(bazi []models.BaziColumn) *models.BaziyuceResu
 
 	// 第二步：定旺衰，识体性 - Vitality Assessment  
 	// Determines the Day Master's strength through four dimensions
-	step2 := s.step2DingWangShuai(bazi)
+	step2 := s.step2DingWangShuai(bazi, trace)
 	result.Steps = append(result.Steps, step2)
 
 	// 第三步：明喜忌，定方向 - Favorable Elements Identification
@@ -109,11 +148,29 @@ func (s *BaziyuceService) Analyze(bazi []models.BaziColumn) *models.BaziyuceResu
 	step4 := s.step4XiGeJu(bazi)
 	result.Steps = append(result.Steps, step4)
 
-	// 第五步：推大运，断流年 - Luck Period Forecasting
-	// Projects future influences and temporal guidance
-	step5 := s.step5TuiDaYun(bazi)
+	// 第五步：看宫位，论六亲 - Palace Analysis
+	// Reads each pillar as a palace of life and relatives; ten gods, 十二长生,
+	// shensha and 空亡 are recomputed from the stems and branches under the school
+	result.Palaces = s.palaceService.Analyze(s.baziService.rebuildColumns(bazi))
+	step5 := s.step5KanGongWei(result.Palaces)
 	result.Steps = append(result.Steps, step5)
 
+	// 第六步：推大运，断流年 - Luck Period Forecasting
+	// Projects future influences and temporal guidance
+	step6 := s.step6TuiDaYun(bazi)
+	result.Steps = append(result.Steps, step6)
+
+	// 调候用神 - Seasonal Adjustment (穷通宝鉴)
+	// Reported as an independent section alongside the six steps
+	result.TiaoHou = s.tiaoHouService.Calculate(bazi)
+
+	// 纳音关系 - Na Yin interactions between each pillar and the day pillar
+	result.NaYin = s.naYinService.Analyze(bazi)
+
+	// 十神统计与性格 - Rule-based personality from the ten-god distribution
+	result.Personality = s.shiShenService.Analyze(bazi, CalcOptions{Profile: s.profile})
+	result.Trace = trace.result()
+
 	return result
 }
 
@@ -191,10 +248,11 @@ func (s *BaziyuceService) step1PaiPanDingPan(bazi []models.BaziColumn) models.An
 //
 // Parameters:
 //   - bazi: Slice of four BaziColumn representing the complete chart
+//   - trace: Provenance recorder for each dimension (nil when not requested)
 //
 // Returns:
 //   - AnalysisStep containing vitality assessment procedures and results
-func (s *BaziyuceService) step2DingWangShuai(bazi []models.BaziColumn) models.AnalysisStep {
+func (s *BaziyuceService) step2DingWangShuai(bazi []models.BaziColumn, trace *traceRecorder) models.AnalysisStep {
 	step := models.AnalysisStep{
 		Title:   "第二步：定旺衰，识体性",
 		Content: []string{},
@@ -215,27 +273,40 @@ func (s *BaziyuceService) step2DingWangShuai(bazi []models.BaziColumn) models.An
 	yueLingStatus := s.getYueLingStatus(riZhu, yueZhi)
 	step.Content = append(step.Content, "1. 得令（看月令）:")
 	step.Content = append(step.Content, fmt.Sprintf("   日主%s在出生月份（%s月）的状态为: %s", riZhu, yueZhi, yueLingStatus))
+	trace.record("strength", "月柱", "得令：日干在月支的十二长生，临官/帝旺/长生/冠带/沐浴为得令",
+		map[string]string{"日干": riZhu, "月支": yueZhi},
+		fmt.Sprintf("十二长生(%s)[%s][%s]=%s（%s）", s.profile.changShengRule(), riZhu, yueZhi,
+			s.profile.changSheng(riZhu, yueZhi), s.profile.ID()), yueLingStatus)
 
 	// 2. 得地 (De Di) - Root/Stability
 	deDiStatus := s.getDeDiStatus(riZhu, bazi)
 	step.Content = append(step.Content, "2. 得地（看根气）:")
 	step.Content = append(step.Content, fmt.Sprintf("   %s", deDiStatus))
+	trace.record("strength", "", "得地：地支藏干中与日主同五行者，本气为强根",
+		map[string]string{"日干": riZhu, "地支": s.joinZhi(bazi)}, "ChartGraph 日干通根边", deDiStatus)
 
 	// 3. 得势 (De Shi) - Support from Allies
 	deShiStatus := s.getDeShiStatus(riZhu, bazi)
 	step.Content = append(step.Content, "3. 得势（看比劫）:")
 	step.Content = append(step.Content, fmt.Sprintf("   %s", deShiStatus))
+	trace.record("strength", "", "得势：天干及藏干中比肩、劫财数量>=2为得势",
+		map[string]string{"日干": riZhu}, "", deShiStatus)
 
 	// 4. 得助 (De Zhu) - Support from Parents/Mentors
 	deZhuStatus := s.getDeZhuStatus(riZhu, bazi)
 	step.Content = append(step.Content, "4. 得助（看印星）:")
 	step.Content = append(step.Content, fmt.Sprintf("   %s", deZhuStatus))
+	trace.record("strength", "", "得助：天干及藏干中正印、偏印数量>=2为得助",
+		map[string]string{"日干": riZhu}, "", deZhuStatus)
 
 	// Comprehensive judgment
 	zongHePanDuan := s.getZongHePanDuan(yueLingStatus, deDiStatus, deShiStatus, deZhuStatus)
 	step.Content = append(step.Content, "")
 	step.Content = append(step.Content, "综合判断:")
 	step.Content = append(step.Content, zongHePanDuan)
+	trace.record("strength", "", "综合判断：得令且得地/得势/得助其一为身旺，失令且有所缺为身弱",
+		map[string]string{"得令": yueLingStatus, "得地": deDiStatus, "得势": deShiStatus, "得助": deZhuStatus},
+		"", zongHePanDuan)
 
 	return step
 }
@@ -385,7 +456,42 @@ func (s *BaziyuceService) step4XiGeJu(bazi []models.BaziColumn) models.AnalysisS
 	return step
 }
 
-// step5TuiDaYun 第五步：推大运，断流年 - Luck Period Forecasting
+// step5KanGongWei 第五步：看宫位，论六亲 - Palace Analysis
+//
+// Each pillar is a palace: year for ancestors and parents, month for siblings
+// and career, the day branch for the spouse, and the hour for children and
+// late life. The rule-based findings come from PalaceService.
+//
+// Parameters:
+//   - palaces: Per-pillar palace analysis
+//
+// Returns:
+//   - AnalysisStep listing each palace's stars and findings
+func (s *BaziyuceService) step5KanGongWei(palaces []models.PalaceAnalysis) models.AnalysisStep {
+	step := models.AnalysisStep{
+		Title:   "第五步：看宫位，论六亲",
+		Content: []string{},
+	}
+
+	step.Content = append(step.Content, "做什么：以四柱为四宫，看各宫所临十神、十二长生、神煞、空亡及刑冲，推断六亲与人生各阶段。")
+	step.Content = append(step.Content, "怎么做：年柱为祖上父母宫，月柱为兄弟事业宫，日支为夫妻宫，时柱为子女晚年宫。")
+
+	for i, palace := range palaces {
+		step.Content = append(step.Content, "")
+		step.Content = append(step.Content, fmt.Sprintf("%d. %s（%s，主%s）：十神%s，日主坐%s",
+			i+1, palace.Palace, palace.Pillar, palace.Domain, s.joinOrNone(palace.ShiShen), palace.ChangSheng))
+		if len(palace.Relations) > 0 {
+			step.Content = append(step.Content, fmt.Sprintf("   冲合：%s", s.joinOrNone(palace.Relations)))
+		}
+		for _, finding := range palace.Findings {
+			step.Content = append(step.Content, "   - "+finding)
+		}
+	}
+
+	return step
+}
+
+// step6TuiDaYun 第六步：推大运，断流年 - Luck Period Forecasting
 //
 // This final step projects future influences based on the established chart,
 // showing how the Luck Pillars and annual influences activate latent potentials.
@@ -400,9 +506,9 @@ func (s *BaziyuceService) step4XiGeJu(bazi []models.BaziColumn) models.AnalysisS
 //
 // Returns:
 //   - AnalysisStep containing luck period forecasting procedures and results
-func (s *BaziyuceService) step5TuiDaYun(bazi []models.BaziColumn) models.AnalysisStep {
+func (s *BaziyuceService) step6TuiDaYun(bazi []models.BaziColumn) models.AnalysisStep {
 	step := models.AnalysisStep{
-		Title:   "第五步：推大运，断流年",
+		Title:   "第六步：推大运，断流年",
 		Content: []string{},
 	}
 
@@ -433,8 +539,8 @@ func (s *BaziyuceService) step5TuiDaYun(bazi []models.BaziColumn) models.Analysi
 
 // getYueLingStatus 获取月令状态 (Determine Monthly Command Status)
 func (s *BaziyuceService) getYueLingStatus(riZhu, yueZhi string) string {
-	// Use the corrected twelve longevity calculation
-	changShengStatus := GetZhangShengPosition(riZhu, yueZhi)
+	// Use the shared twelve longevity lookup with the school's convention
+	changShengStatus := s.profile.changSheng(riZhu, yueZhi)
 	
 	// Determine command authority status
 	deLingStates := []string{"临官", "帝旺", "长生", "冠带", "沐浴"}
@@ -458,23 +564,18 @@ func (s *BaziyuceService) getYueLingStatus(riZhu, yueZhi string) string {
 }
 
 // getDeDiStatus 得地状态分析
+//
+// 根气取自命局关系图中日干的通根边：本气为强根，中气、余气为微根。
 func (s *BaziyuceService) getDeDiStatus(riZhu string, bazi []models.BaziColumn) string {
-	riZhuWuXing := tianGanWuXing[riZhu]
 	qiangGenCount := 0
 	weiGenCount := 0
 	
-	// 检查地支藏干
-	for _, column := range bazi {
-		cangGan := column.CangGan
-		for _, gan := range cangGan {
-			if gan != "" && tianGanWuXing[gan] == riZhuWuXing {
-				// 判断是否为本气强根
-				if s.isBenQiQiangGen(column.Zhi, gan) {
-					qiangGenCount++
-				} else {
-					weiGenCount++
-				}
-			}
+	graph := s.graphService.Build(bazi, CalcOptions{Profile: s.profile})
+	for _, root := range s.graphService.RootsOf(graph, 2) {
+		if root.Strength == "强" {
+			qiangGenCount++
+		} else {
+			weiGenCount++
 		}
 	}
 	
@@ -495,19 +596,6 @@ func (s *BaziyuceService) getDeDiStatus(riZhu string, bazi []models.BaziColumn)
 	}
 }
 
-// isBenQiQiangGen 判断是否为本气强根
-func (s *BaziyuceService) isBenQiQiangGen(zhi, gan string) bool {
-	benQiMap := map[string]string{
-		"寅": "甲", "卯": "乙", "巳": "丙", "午": "丁", "辰": "戊", "戌": "戊", "丑": "己", "未": "己",
-		"申": "庚", "酉": "辛", "亥": "壬", "子": "癸",
-	}
-	
-	if benQi, exists := benQiMap[zhi]; exists {
-		return benQi == gan
-	}
-	return false
-}
-
 // getDeShiStatus 得势状态分析（比劫）
 func (s *BaziyuceService) getDeShiStatus(riZhu string, bazi []models.BaziColumn) string {
 	biJieCount := 0
@@ -522,9 +610,8 @@ func (s *BaziyuceService) getDeShiStatus(riZhu string, bazi []models.BaziColumn)
 	
 	// 统计地支藏干中的比肩、劫财
 	for _, column := range bazi {
-		fuXing := column.FuXing
-		for _, fx := range fuXing {
-			if fx == "比肩" || fx == "劫财" {
+		for _, item := range column.CangGan {
+			if item.ShiShen == "比肩" || item.ShiShen == "劫财" {
 				biJieCount++
 			}
 		}
@@ -553,9 +640,8 @@ func (s *BaziyuceService) getDeZhuStatus(riZhu string, bazi []models.BaziColumn)
 	
 	// 统计地支藏干中的正印、偏印
 	for _, column := range bazi {
-		fuXing := column.FuXing
-		for _, fx := range fuXing {
-			if fx == "正印" || fx == "偏印" {
+		for _, item := range column.CangGan {
+			if item.ShiShen == "正印" || item.ShiShen == "偏印" {
 				yinXingCount++
 			}
 		}
@@ -634,13 +720,29 @@ func (s *BaziyuceService) getShengWuXing(wuxing string) string {
 	return shengRelations[wuxing]
 }
 
-func (s *BaziyuceService) joinCangGan(cangGan []string) string {
+func (s *BaziyuceService) joinZhi(bazi []models.BaziColumn) string {
 	result := ""
-	for i, gan := range cangGan {
+	for _, column := range bazi {
+		result += column.Zhi
+	}
+	return result
+}
+
+// joinOrNone 以顿号连接，空列表返回"无"
+func (s *BaziyuceService) joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "无"
+	}
+	return strings.Join(items, "、")
+}
+
+func (s *BaziyuceService) joinCangGan(cangGan []models.CangGanItem) string {
+	result := ""
+	for i, item := range cangGan {
 		if i > 0 {
 			result += "、"
 		}
-		result += gan
+		result += item.Gan
 	}
 	if result == "" {
 		result = "无"
@@ -741,15 +843,9 @@ func (s *BaziyuceService) analyzeDiZhiGuanXi(bazi []models.BaziColumn) string {
 	relations := []string{}
 
 	// 检查是否有冲
-	chongPairs := map[string]string{
-		"子": "午", "丑": "未", "寅": "申", "卯": "酉",
-		"辰": "戌", "巳": "亥", "午": "子", "未": "丑",
-		"申": "寅", "酉": "卯", "戌": "辰", "亥": "巳",
-	}
-
 	for i := 0; i < 4; i++ {
 		for j := i + 1; j < 4; j++ {
-			if chongPairs[diZhi[i]] == diZhi[j] {
+			if isDiZhiChong(diZhi[i], diZhi[j]) {
 				relations = append(relations, fmt.Sprintf("%s与%s相冲", diZhi[i], diZhi[j]))
 			}
 		}
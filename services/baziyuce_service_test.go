@@ -0,0 +1,72 @@
+package services
+
+import (
+	"auspire/models"
+	"testing"
+)
+
+func TestYueLingStatusBySchool(t *testing.T) {
+	tests := []struct {
+		school string
+		riZhu  string
+		yueZhi string
+		want   string
+	}{
+		{"legacy", "甲", "寅", "得令（临官），趋势强"},
+		{"legacy", "癸", "申", "得令（长生），趋势强"},
+		{"traditional", "癸", "申", "失令（死），趋势弱"},
+		{"traditional", "乙", "午", "得令（长生），趋势强"},
+		{"legacy", "乙", "午", "失令（死），趋势弱"},
+	}
+
+	for _, tt := range tests {
+		profile, err := GetSchoolProfile(tt.school)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := newBaziyuceService(profile).getYueLingStatus(tt.riZhu, tt.yueZhi); got != tt.want {
+			t.Errorf("%s getYueLingStatus(%s, %s) = %s, want %s", tt.school, tt.riZhu, tt.yueZhi, got, tt.want)
+		}
+	}
+}
+
+// bareChart 只含干支的四柱：庚午 甲申 癸未 丁巳
+func bareChart() []models.BaziColumn {
+	return []models.BaziColumn{{Gan: "庚", Zhi: "午"}, {Gan: "甲", Zhi: "申"}, {Gan: "癸", Zhi: "未"}, {Gan: "丁", Zhi: "巳"}}
+}
+
+func TestAnalyzePalacesFromBarePillars(t *testing.T) {
+	tests := []struct {
+		school     string
+		changSheng string
+	}{
+		{"legacy", "养"},
+		{"traditional", "墓"},
+	}
+
+	for _, tt := range tests {
+		profile, err := GetSchoolProfile(tt.school)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bazi := bareChart()
+		// 调用方传入的派生字段不予采信
+		bazi[2].XingYun = "帝旺"
+		bazi[2].CangGan = []models.CangGanItem{{Gan: "丙", ShiShen: "正财"}}
+
+		result := NewBaziyuceService().Analyze(bazi, CalcOptions{Profile: profile})
+		spouse := result.Palaces[2]
+		if len(spouse.Findings) == 0 {
+			t.Errorf("%s 夫妻宫 findings is empty", tt.school)
+		}
+		if len(spouse.ShiShen) != 1 || spouse.ShiShen[0] != "七杀" {
+			t.Errorf("%s 夫妻宫 shiShen = %v, want [七杀]", tt.school, spouse.ShiShen)
+		}
+		if spouse.ChangSheng != tt.changSheng {
+			t.Errorf("%s 夫妻宫 changSheng = %s, want %s", tt.school, spouse.ChangSheng, tt.changSheng)
+		}
+		if len(spouse.ShenSha) == 0 {
+			t.Errorf("%s 夫妻宫 shenSha is empty", tt.school)
+		}
+	}
+}
@@ -1,42 +1,72 @@
 package services
 
+import "auspire/models"
+
 // CangGanService 藏干服务
-type CangGanService struct{}
+type CangGanService struct {
+	data map[string][]CangGanEntry
+}
+
+// CangGanEntry 地支所藏之干：本气、中气或余气及其力量占比
+type CangGanEntry struct {
+	Gan    string `json:"gan"`
+	Role   string `json:"role"`   // 本气/中气/余气
+	Weight int    `json:"weight"` // 力量占比（百分比）
+}
 
 var (
-	// 地支藏干对照表
-	cangGanData = map[string][]string{
-		"子": {"癸"},
-		"丑": {"己", "辛", "癸"},
-		"寅": {"甲", "丙", "戊"},
-		"卯": {"乙"},
-		"辰": {"戊", "乙", "癸"},
-		"巳": {"丙", "戊", "庚"},
-		"午": {"丁", "己"},
-		"未": {"己", "丁", "乙"},
-		"申": {"庚", "壬", "戊"},
-		"酉": {"辛"},
-		"戌": {"戊", "辛", "丁"},
-		"亥": {"壬", "甲"},
+	// 地支藏干对照表（通行表次序，辰藏戊乙癸、巳藏丙戊庚等）
+	cangGanData = map[string][]CangGanEntry{
+		"子": {{"癸", "本气", 100}},
+		"丑": {{"己", "本气", 60}, {"辛", "中气", 30}, {"癸", "余气", 10}},
+		"寅": {{"甲", "本气", 60}, {"丙", "中气", 30}, {"戊", "余气", 10}},
+		"卯": {{"乙", "本气", 100}},
+		"辰": {{"戊", "本气", 60}, {"乙", "余气", 10}, {"癸", "中气", 30}},
+		"巳": {{"丙", "本气", 60}, {"戊", "余气", 10}, {"庚", "中气", 30}},
+		"午": {{"丁", "本气", 70}, {"己", "中气", 30}},
+		"未": {{"己", "本气", 60}, {"丁", "余气", 10}, {"乙", "中气", 30}},
+		"申": {{"庚", "本气", 60}, {"壬", "中气", 30}, {"戊", "余气", 10}},
+		"酉": {{"辛", "本气", 100}},
+		"戌": {{"戊", "本气", 60}, {"辛", "余气", 10}, {"丁", "中气", 30}},
+		"亥": {{"壬", "本气", 70}, {"甲", "中气", 30}},
 	}
 )
 
 func NewCangGanService() *CangGanService {
-	return &CangGanService{}
+	return newCangGanService(defaultSchoolProfile())
+}
+
+// newCangGanService 按流派的藏干表创建藏干服务
+func newCangGanService(profile *SchoolProfile) *CangGanService {
+	return &CangGanService{data: profile.CangGan}
 }
 
-// Calculate 计算地支藏干
+// Calculate 计算地支藏干（按流派藏干表次序）
 func (s *CangGanService) Calculate(zhi string) []string {
-	if cangGan, exists := cangGanData[zhi]; exists {
-		// 返回副本避免外部修改
-		result := make([]string, len(cangGan))
-		copy(result, cangGan)
-		return result
+	entries := s.data[zhi]
+	result := make([]string, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.Gan
 	}
-	return []string{}
+	return result
 }
 
 // GetAllCangGan 获取所有藏干数据（用于其他服务）
-func (s *CangGanService) GetAllCangGan() map[string][]string {
-	return cangGanData
-}
\ No newline at end of file
+func (s *CangGanService) GetAllCangGan() map[string][]CangGanEntry {
+	return s.data
+}
+
+// Items 计算地支藏干明细：按流派藏干表次序，附本气/中气/余气、五行及力量占比
+func (s *CangGanService) Items(zhi string) []models.CangGanItem {
+	entries := s.data[zhi]
+	items := make([]models.CangGanItem, len(entries))
+	for i, entry := range entries {
+		items[i] = models.CangGanItem{
+			Gan:    entry.Gan,
+			Role:   entry.Role,
+			Weight: entry.Weight,
+			WuXing: tianGanWuXing[entry.Gan],
+		}
+	}
+	return items
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"auspire/models"
+	"auspire/services/solarterm"
+	"fmt"
+	"time"
+)
+
+// DaYunService 大运流年服务
+type DaYunService struct {
+	naYinService    *NaYinService
+	kongWangService *KongWangService
+}
+
+const (
+	// 排出的大运步数
+	daYunCount = 8
+	// 排出的流年数
+	liuNianCount = 10
+)
+
+func NewDaYunService() *DaYunService {
+	return &DaYunService{
+		naYinService:    NewNaYinService(),
+		kongWangService: NewKongWangService(),
+	}
+}
+
+// CalculateDaYun 排大运
+//
+// 阳年男命、阴年女命顺行，阴年男命、阳年女命逆行，自月柱起逐柱推排。
+// 起运岁数按出生日至下一个节（顺行）或上一个节（逆行）的天数折算：
+// 三天为一岁，一天为四个月。
+func (s *DaYunService) CalculateDaYun(bazi []models.BaziColumn, birth time.Time, gender string) *models.DaYunInfo {
+	yearGan := bazi[0].Gan
+	forward := IsYangGan(yearGan) == (gender == "男")
+
+	var days int
+	direction := "逆行"
+	if forward {
+		direction = "顺行"
+		_, jie := solarterm.NextJie(birth)
+		days = daysBetween(birth, jie)
+	} else {
+		_, jie := solarterm.PrevJie(birth)
+		days = daysBetween(jie, birth)
+	}
+	startYears, startMonths := days/3, days%3*4
+
+	yinYang := "阴"
+	if IsYangGan(yearGan) {
+		yinYang = "阳"
+	}
+	info := &models.DaYunInfo{
+		Direction:      direction,
+		StartAgeYears:  startYears,
+		StartAgeMonths: startMonths,
+		Description: fmt.Sprintf("%s年（%s）%s命，大运%s；出生距%s节%d天，%d岁%d个月起运",
+			yinYang, yearGan, gender, direction, map[bool]string{true: "下一", false: "上一"}[forward], days, startYears, startMonths),
+		Pillars: []models.DaYunPillar{},
+	}
+
+	dayNaYin := s.naYinService.Calculate(bazi[2].Gan, bazi[2].Zhi)
+	startYear := birth.AddDate(startYears, startMonths, 0).Year()
+	monthIndex := jiaZiIndex(bazi[1].Gan, bazi[1].Zhi)
+	if monthIndex < 0 {
+		return info
+	}
+
+	step := 1
+	if !forward {
+		step = -1
+	}
+	for i := 0; i < daYunCount; i++ {
+		index := ((monthIndex+step*(i+1))%60 + 60) % 60
+		gan, zhi := tianGan[index%10], diZhi[index%12]
+		naYin := s.naYinService.Calculate(gan, zhi)
+		relation, _, _ := s.naYinService.Relate(dayNaYin, naYin)
+		kongWang, events := s.kongWangService.MarkPeriod(bazi, zhi)
+		info.Pillars = append(info.Pillars, models.DaYunPillar{
+			Gan:            gan,
+			Zhi:            zhi,
+			NaYin:          naYin,
+			StartAge:       startYears + i*10,
+			StartYear:      startYear + i*10,
+			EndYear:        startYear + i*10 + 9,
+			NaYinRelation:  relation,
+			KongWang:       kongWang,
+			KongWangEvents: events,
+		})
+	}
+
+	return info
+}
+
+// CalculateLiuNian 排流年：自 fromYear 起连续 liuNianCount 年
+//
+// birthYear 为 0 时不计岁数（如录入四柱排盘）。
+func (s *DaYunService) CalculateLiuNian(bazi []models.BaziColumn, fromYear, birthYear int) []models.LiuNianPillar {
+	dayNaYin := s.naYinService.Calculate(bazi[2].Gan, bazi[2].Zhi)
+	result := []models.LiuNianPillar{}
+
+	for year := fromYear; year < fromYear+liuNianCount; year++ {
+		index := ((year-4)%60 + 60) % 60
+		gan, zhi := tianGan[index%10], diZhi[index%12]
+		naYin := s.naYinService.Calculate(gan, zhi)
+		relation, _, _ := s.naYinService.Relate(dayNaYin, naYin)
+		kongWang, events := s.kongWangService.MarkPeriod(bazi, zhi)
+
+		pillar := models.LiuNianPillar{
+			Year:           year,
+			Gan:            gan,
+			Zhi:            zhi,
+			NaYin:          naYin,
+			NaYinRelation:  relation,
+			KongWang:       kongWang,
+			KongWangEvents: events,
+		}
+		if birthYear > 0 && year > birthYear {
+			pillar.Age = year - birthYear
+		}
+		result = append(result, pillar)
+	}
+
+	return result
+}
+
+// jiaZiIndex 干支在六十甲子中的序号，非法组合返回-1
+func jiaZiIndex(gan, zhi string) int {
+	for i := 0; i < 60; i++ {
+		if tianGan[i%10] == gan && diZhi[i%12] == zhi {
+			return i
+		}
+	}
+	return -1
+}
+
+// daysBetween 两个日期相差的天数（只计日期部分）
+func daysBetween(from, to time.Time) int {
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDay.Sub(fromDay).Hours() / 24)
+}
@@ -9,24 +9,21 @@ type FuXingService struct {
 }
 
 func NewFuXingService() *FuXingService {
+	return newFuXingService(defaultSchoolProfile())
+}
+
+func newFuXingService(profile *SchoolProfile) *FuXingService {
 	return &FuXingService{
 		zhuXingService: NewZhuXingService(),
-		cangGanService: NewCangGanService(),
+		cangGanService: newCangGanService(profile),
 	}
 }
 
-// Calculate 计算副星（基于地支藏干的十神关系）
-func (s *FuXingService) Calculate(dayGan string, column models.BaziColumn) []string {
-	var fuXing []string
-
-	// 分析地支藏干的十神关系
-	cangGan := s.cangGanService.Calculate(column.Zhi)
-	for _, cGan := range cangGan {
-		zhuXing := s.zhuXingService.Calculate(dayGan, cGan)
-		if zhuXing != "" {
-			fuXing = append(fuXing, zhuXing)
-		}
+// Calculate 计算副星：地支藏干明细及各藏干对日干的十神
+func (s *FuXingService) Calculate(dayGan string, column models.BaziColumn) []models.CangGanItem {
+	items := s.cangGanService.Items(column.Zhi)
+	for i := range items {
+		items[i].ShiShen = s.zhuXingService.Calculate(dayGan, items[i].Gan)
 	}
-
-	return fuXing
-}
\ No newline at end of file
+	return items
+}
@@ -0,0 +1,77 @@
+package services
+
+var (
+	// 天干五合
+	tianGanHe = map[string]string{
+		"甲": "己", "己": "甲", "乙": "庚", "庚": "乙", "丙": "辛",
+		"辛": "丙", "丁": "壬", "壬": "丁", "戊": "癸", "癸": "戊",
+	}
+
+	// 天干相冲（七冲）
+	tianGanChong = map[string]string{
+		"甲": "庚", "庚": "甲", "乙": "辛", "辛": "乙",
+		"丙": "壬", "壬": "丙", "丁": "癸", "癸": "丁",
+	}
+
+	// 地支六合
+	diZhiLiuHe = map[string]string{
+		"子": "丑", "丑": "子", "寅": "亥", "亥": "寅", "卯": "戌", "戌": "卯",
+		"辰": "酉", "酉": "辰", "巳": "申", "申": "巳", "午": "未", "未": "午",
+	}
+
+	// 地支六冲
+	diZhiChong = map[string]string{
+		"子": "午", "丑": "未", "寅": "申", "卯": "酉", "辰": "戌", "巳": "亥",
+		"午": "子", "未": "丑", "申": "寅", "酉": "卯", "戌": "辰", "亥": "巳",
+	}
+
+	// 地支六害
+	diZhiHai = map[string]string{
+		"子": "未", "未": "子", "丑": "午", "午": "丑", "寅": "巳", "巳": "寅",
+		"卯": "辰", "辰": "卯", "申": "亥", "亥": "申", "酉": "戌", "戌": "酉",
+	}
+
+	// 地支三合局
+	diZhiSanHe = map[string]string{
+		"申": "申子辰", "子": "申子辰", "辰": "申子辰",
+		"寅": "寅午戌", "午": "寅午戌", "戌": "寅午戌",
+		"巳": "巳酉丑", "酉": "巳酉丑", "丑": "巳酉丑",
+		"亥": "亥卯未", "卯": "亥卯未", "未": "亥卯未",
+	}
+
+	// 地支三刑（所刑之支）：子卯无礼、寅巳申无恩、丑戌未恃势，辰午酉亥自刑
+	diZhiXing = map[string]string{
+		"子": "卯", "卯": "子", "寅": "巳", "巳": "申", "申": "寅",
+		"丑": "戌", "戌": "未", "未": "丑", "辰": "辰", "午": "午", "酉": "酉", "亥": "亥",
+	}
+)
+
+// isTianGanHe 判断两天干是否五合
+func isTianGanHe(a, b string) bool {
+	return tianGanHe[a] == b
+}
+
+// isTianGanChong 判断两天干是否相冲
+func isTianGanChong(a, b string) bool {
+	return tianGanChong[a] == b
+}
+
+// isDiZhiLiuHe 判断两地支是否六合
+func isDiZhiLiuHe(a, b string) bool {
+	return diZhiLiuHe[a] == b
+}
+
+// isDiZhiChong 判断两地支是否六冲
+func isDiZhiChong(a, b string) bool {
+	return diZhiChong[a] == b
+}
+
+// isDiZhiHai 判断两地支是否六害
+func isDiZhiHai(a, b string) bool {
+	return diZhiHai[a] == b
+}
+
+// isDiZhiSanHe 判断两地支是否同属一个三合局（不含同一地支）
+func isDiZhiSanHe(a, b string) bool {
+	return a != b && diZhiSanHe[a] != "" && diZhiSanHe[a] == diZhiSanHe[b]
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"strings"
+)
+
+// ChartGraphService 命局关系图服务
+//
+// 将四柱的天干、地支、藏干建模为节点，通根、透干、生克、合冲建模为带强度的边，
+// 供前端绘制干支作用图，也供旺衰分析统一查询根气。
+type ChartGraphService struct {
+	profile        *SchoolProfile
+	cangGanService *CangGanService
+	zhuXingService *ZhuXingService
+}
+
+var (
+	// 柱位节点前缀
+	graphPillarKeys = []string{"year", "month", "day", "hour"}
+
+	// 藏干本气、中气、余气对应的根气强度与权重
+	cangGanRootStrength = map[string]string{"本气": "强", "中气": "中", "余气": "弱"}
+	cangGanRootWeight   = map[string]float64{"本气": 1.0, "中气": 0.6, "余气": 0.3}
+
+	// 柱间距离对应的作用强度（同柱/相邻、隔一柱、遥隔）
+	graphDistanceStrength = []string{"强", "强", "中", "弱"}
+	graphDistanceWeight   = []float64{1.0, 1.0, 0.6, 0.3}
+
+	// DOT 导出时各类边的颜色
+	graphEdgeColors = map[string]string{
+		"藏": "gray", "通根": "darkgreen", "透干": "blue",
+		"生": "forestgreen", "克": "red", "合": "purple", "冲": "orange",
+	}
+)
+
+func NewChartGraphService() *ChartGraphService {
+	return newChartGraphService(defaultSchoolProfile())
+}
+
+// newChartGraphService 按流派创建关系图服务，藏干节点及根气边取该流派的藏干表
+func newChartGraphService(profile *SchoolProfile) *ChartGraphService {
+	return &ChartGraphService{
+		profile:        profile,
+		cangGanService: newCangGanService(profile),
+		zhuXingService: NewZhuXingService(),
+	}
+}
+
+// withProfile 返回绑定指定流派的服务实例，流派相同时返回自身
+func (s *ChartGraphService) withProfile(profile *SchoolProfile) *ChartGraphService {
+	if profile == s.profile {
+		return s
+	}
+	return newChartGraphService(profile)
+}
+
+// Build 构建命局关系图
+func (s *ChartGraphService) Build(bazi []models.BaziColumn, opts CalcOptions) *models.ChartGraph {
+	s = s.withProfile(opts.profile())
+
+	columnNames := []string{"年", "月", "日", "时"}
+	graph := &models.ChartGraph{
+		Nodes:  []models.GraphNode{},
+		Edges:  []models.GraphEdge{},
+		School: s.profile.ID(),
+	}
+
+	// 节点：天干、地支、藏干
+	for i, column := range bazi {
+		key := graphPillarKeys[i]
+		graph.Nodes = append(graph.Nodes,
+			models.GraphNode{ID: key + "_gan", Kind: "天干", Label: column.Gan, Pillar: columnNames[i] + "柱", WuXing: tianGanWuXing[column.Gan]},
+			models.GraphNode{ID: key + "_zhi", Kind: "地支", Label: column.Zhi, Pillar: columnNames[i] + "柱", WuXing: diZhiWuXing[column.Zhi]},
+		)
+		for j, item := range s.cangGanService.Items(column.Zhi) {
+			cangID := fmt.Sprintf("%s_cang_%d", key, j)
+			graph.Nodes = append(graph.Nodes,
+				models.GraphNode{ID: cangID, Kind: "藏干", Label: item.Gan, Pillar: columnNames[i] + "柱", WuXing: item.WuXing})
+			graph.Edges = append(graph.Edges, models.GraphEdge{
+				From: key + "_zhi", To: cangID, Type: "藏",
+				Strength: cangGanRootStrength[item.Role], Weight: cangGanRootWeight[item.Role],
+				Description: fmt.Sprintf("%s中藏%s", column.Zhi, item.Gan),
+			})
+		}
+	}
+
+	// 通根与透干
+	for i, column := range bazi {
+		for j, target := range bazi {
+			for k, item := range s.cangGanService.Items(target.Zhi) {
+				cangID := fmt.Sprintf("%s_cang_%d", graphPillarKeys[j], k)
+				if item.WuXing == tianGanWuXing[column.Gan] {
+					graph.Edges = append(graph.Edges, models.GraphEdge{
+						From: graphPillarKeys[i] + "_gan", To: cangID, Type: "通根",
+						Strength: cangGanRootStrength[item.Role], Weight: cangGanRootWeight[item.Role],
+						Description: fmt.Sprintf("%s干%s通根%s支%s（藏%s）", columnNames[i], column.Gan, columnNames[j], target.Zhi, item.Gan),
+					})
+				}
+				if item.Gan == column.Gan {
+					graph.Edges = append(graph.Edges, models.GraphEdge{
+						From: cangID, To: graphPillarKeys[i] + "_gan", Type: "透干",
+						Strength: cangGanRootStrength[item.Role], Weight: cangGanRootWeight[item.Role],
+						Description: fmt.Sprintf("%s支藏%s透出于%s干", columnNames[j], item.Gan, columnNames[i]),
+					})
+				}
+			}
+		}
+	}
+
+	// 天干之间、地支之间的生克合冲
+	for i := 0; i < len(bazi); i++ {
+		for j := i + 1; j < len(bazi); j++ {
+			distance := j - i
+			s.addInteractions(graph, graphPillarKeys[i]+"_gan", graphPillarKeys[j]+"_gan", bazi[i].Gan, bazi[j].Gan, distance, true)
+			s.addInteractions(graph, graphPillarKeys[i]+"_zhi", graphPillarKeys[j]+"_zhi", bazi[i].Zhi, bazi[j].Zhi, distance, false)
+		}
+	}
+
+	// 同柱干支的生克（盖头、截脚）
+	for i, column := range bazi {
+		s.addShengKe(graph, graphPillarKeys[i]+"_gan", graphPillarKeys[i]+"_zhi",
+			column.Gan, column.Zhi, tianGanWuXing[column.Gan], diZhiWuXing[column.Zhi], 0)
+	}
+
+	return graph
+}
+
+// addInteractions 添加两个同类节点间的生克合冲边
+func (s *ChartGraphService) addInteractions(graph *models.ChartGraph, fromID, toID, a, b string, distance int, isGan bool) {
+	var wuXingA, wuXingB string
+	var he, chong bool
+	if isGan {
+		wuXingA, wuXingB = tianGanWuXing[a], tianGanWuXing[b]
+		he, chong = isTianGanHe(a, b), isTianGanChong(a, b)
+	} else {
+		wuXingA, wuXingB = diZhiWuXing[a], diZhiWuXing[b]
+		he, chong = isDiZhiLiuHe(a, b), isDiZhiChong(a, b)
+	}
+
+	s.addShengKe(graph, fromID, toID, a, b, wuXingA, wuXingB, distance)
+
+	if he {
+		graph.Edges = append(graph.Edges, models.GraphEdge{
+			From: fromID, To: toID, Type: "合",
+			Strength: graphDistanceStrength[distance], Weight: graphDistanceWeight[distance],
+			Description: fmt.Sprintf("%s%s相合", a, b),
+		})
+	}
+	if chong {
+		graph.Edges = append(graph.Edges, models.GraphEdge{
+			From: fromID, To: toID, Type: "冲",
+			Strength: graphDistanceStrength[distance], Weight: graphDistanceWeight[distance],
+			Description: fmt.Sprintf("%s%s相冲", a, b),
+		})
+	}
+}
+
+// addShengKe 按五行生克添加有向边，方向由施动者指向受动者
+func (s *ChartGraphService) addShengKe(graph *models.ChartGraph, idA, idB, a, b, wuXingA, wuXingB string, distance int) {
+	edge := models.GraphEdge{
+		Strength: graphDistanceStrength[distance],
+		Weight:   graphDistanceWeight[distance],
+	}
+
+	switch {
+	case s.zhuXingService.isShengRelation(wuXingA, wuXingB):
+		edge.From, edge.To, edge.Type = idA, idB, "生"
+		edge.Description = fmt.Sprintf("%s(%s)生%s(%s)", a, wuXingA, b, wuXingB)
+	case s.zhuXingService.isShengRelation(wuXingB, wuXingA):
+		edge.From, edge.To, edge.Type = idB, idA, "生"
+		edge.Description = fmt.Sprintf("%s(%s)生%s(%s)", b, wuXingB, a, wuXingA)
+	case s.zhuXingService.isKeRelation(wuXingA, wuXingB):
+		edge.From, edge.To, edge.Type = idA, idB, "克"
+		edge.Description = fmt.Sprintf("%s(%s)克%s(%s)", a, wuXingA, b, wuXingB)
+	case s.zhuXingService.isKeRelation(wuXingB, wuXingA):
+		edge.From, edge.To, edge.Type = idB, idA, "克"
+		edge.Description = fmt.Sprintf("%s(%s)克%s(%s)", b, wuXingB, a, wuXingA)
+	default:
+		return
+	}
+
+	graph.Edges = append(graph.Edges, edge)
+}
+
+// RootsOf 返回指定柱天干的全部通根边
+func (s *ChartGraphService) RootsOf(graph *models.ChartGraph, pillarIndex int) []models.GraphEdge {
+	roots := []models.GraphEdge{}
+	ganID := graphPillarKeys[pillarIndex] + "_gan"
+	for _, edge := range graph.Edges {
+		if edge.Type == "通根" && edge.From == ganID {
+			roots = append(roots, edge)
+		}
+	}
+	return roots
+}
+
+// ToDOT 将命局关系图导出为 Graphviz DOT 格式
+func (s *ChartGraphService) ToDOT(graph *models.ChartGraph) string {
+	var sb strings.Builder
+	sb.WriteString("digraph bazi {\n")
+	sb.WriteString("  rankdir=TB;\n")
+	sb.WriteString("  node [fontname=\"sans-serif\"];\n")
+
+	for _, node := range graph.Nodes {
+		shape := "box"
+		switch node.Kind {
+		case "地支":
+			shape = "ellipse"
+		case "藏干":
+			shape = "plaintext"
+		}
+		fmt.Fprintf(&sb, "  %q [label=%q, shape=%s];\n", node.ID, node.Label+"\n"+node.Pillar+node.Kind, shape)
+	}
+
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&sb, "  %q -> %q [label=%q, color=%s, penwidth=%.1f];\n",
+			edge.From, edge.To, edge.Type, graphEdgeColors[edge.Type], 1+edge.Weight*2)
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"strings"
+)
+
+// GroupService 多人关系矩阵服务
+//
+// 适用于家庭、创业团队等多人命局的合看：成员各自排盘、定喜用后，两两比对
+// 天干五合与相冲、地支六合三合与冲害，以及一方八字五行对另一方喜忌的助损，
+// 再汇总全体五行分布，给出缺失五行、共同喜用及最和谐、最冲突的组合等群体结论。
+type GroupService struct {
+	profile           *SchoolProfile
+	baziService       *BaziService
+	xiYongShenService *XiYongShenService
+}
+
+const (
+	// 干支互动计分：合加分、冲害减分
+	groupGanHeScore    = 2
+	groupGanChongScore = -2
+	groupLiuHeScore    = 2
+	groupSanHeScore    = 1
+	groupChongScore    = -2
+	groupHaiScore      = -1
+)
+
+func NewGroupService() *GroupService {
+	return newGroupService(defaultSchoolProfile())
+}
+
+// newGroupService 按流派创建多人关系服务，排盘及喜用神均采用该流派
+func newGroupService(profile *SchoolProfile) *GroupService {
+	return &GroupService{
+		profile:           profile,
+		baziService:       newBaziService(profile),
+		xiYongShenService: newXiYongShenService(profile),
+	}
+}
+
+// withProfile 返回绑定指定流派的服务实例，流派相同时返回自身
+func (s *GroupService) withProfile(profile *SchoolProfile) *GroupService {
+	if profile == s.profile {
+		return s
+	}
+	return newGroupService(profile)
+}
+
+// Analyze 多人关系矩阵
+//
+// matrix[i][j] 为成员 j 的八字干支中为成员 i 用神、喜神的字数减去忌神、仇神的字数；
+// 两两互动综合分为干支合冲计分与双向五行助益之和。
+func (s *GroupService) Analyze(req models.GroupRequest, opts CalcOptions) (*models.GroupResult, error) {
+	s = s.withProfile(opts.profile())
+	chartOpts := CalcOptions{Profile: opts.Profile}
+
+	result := &models.GroupResult{
+		Members:      []models.GroupMember{},
+		Pairs:        []models.GroupPair{},
+		Matrix:       [][]int{},
+		WuXingTotals: map[string]int{},
+		Findings:     []string{},
+		School:       s.profile.ID(),
+	}
+	for _, wuxing := range wuXingList {
+		result.WuXingTotals[wuxing] = 0
+	}
+
+	roles := []map[string]string{}
+	for _, member := range req.Members {
+		chart, err := s.baziService.CalculateBazi(member, chartOpts)
+		if err != nil {
+			return &models.GroupResult{Error: fmt.Sprintf("%s排盘失败: %s", member.Name, err.Error())}, err
+		}
+		xiYong := s.xiYongShenService.Calculate(chart.Bazi, chartOpts)
+
+		count := map[string]int{}
+		for _, wuxing := range wuXingList {
+			count[wuxing] = 0
+		}
+		for _, column := range chart.Bazi {
+			count[column.GanWuXing]++
+			count[column.ZhiWuXing]++
+		}
+		for wuxing, n := range count {
+			result.WuXingTotals[wuxing] += n
+		}
+
+		result.Members = append(result.Members, models.GroupMember{
+			Name:          member.Name,
+			Bazi:          chart.Bazi,
+			RiZhu:         xiYong.RiZhu,
+			RiZhuStrength: xiYong.RiZhuStrength,
+			XiYongShen:    xiYong.XiYongShen,
+			WuXingCount:   count,
+		})
+		roles = append(roles, xiYong.WuXingRoles)
+	}
+
+	members := result.Members
+	influences := make([][]models.GroupInfluence, len(members))
+	for i := range members {
+		row := make([]int, len(members))
+		influences[i] = make([]models.GroupInfluence, len(members))
+		for j := range members {
+			if i == j {
+				continue
+			}
+			influences[i][j] = s.influence(members[j], members[i], roles[i])
+			row[j] = len(influences[i][j].Favorable) - len(influences[i][j].Unfavorable)
+		}
+		result.Matrix = append(result.Matrix, row)
+	}
+
+	for i := range members {
+		for j := i + 1; j < len(members); j++ {
+			pair := models.GroupPair{
+				A:            i,
+				B:            j,
+				GanRelations: []string{},
+				ZhiRelations: []string{},
+				AToB:         influences[j][i],
+				BToA:         influences[i][j],
+			}
+			pair.Score = s.relate(&pair, members[i], members[j]) + result.Matrix[i][j] + result.Matrix[j][i]
+			result.Pairs = append(result.Pairs, pair)
+		}
+	}
+
+	result.Findings = s.findings(result, roles)
+	return result, nil
+}
+
+// influence 成员 from 的八字干支对成员 to 的喜忌助损
+func (s *GroupService) influence(from, to models.GroupMember, roles map[string]string) models.GroupInfluence {
+	favorable, unfavorable := rolesInChart(roles, from.Bazi)
+	influence := models.GroupInfluence{
+		From:        from.Name,
+		To:          to.Name,
+		Favorable:   favorable,
+		Unfavorable: unfavorable,
+	}
+
+	switch net := len(favorable) - len(unfavorable); {
+	case net > 0:
+		influence.Effect = "助"
+	case net < 0:
+		influence.Effect = "损"
+	default:
+		influence.Effect = "平"
+	}
+
+	parts := []string{}
+	if len(favorable) > 0 {
+		parts = append(parts, fmt.Sprintf("%s为其喜用", strings.Join(favorable, "")))
+	}
+	if len(unfavorable) > 0 {
+		parts = append(parts, fmt.Sprintf("%s为其忌仇", strings.Join(unfavorable, "")))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "干支于其喜忌无涉")
+	}
+	influence.Description = fmt.Sprintf("%s八字中%s，于%s为%s", from.Name, strings.Join(parts, "，"), to.Name, influence.Effect)
+
+	return influence
+}
+
+// relate 两成员四柱干支逐一比对合冲，返回干支互动计分
+func (s *GroupService) relate(pair *models.GroupPair, a, b models.GroupMember) int {
+	columnNames := []string{"年柱", "月柱", "日柱", "时柱"}
+	score := 0
+
+	for p, x := range a.Bazi {
+		for q, y := range b.Bazi {
+			left := fmt.Sprintf("%s%s%s", a.Name, columnNames[p], x.Gan)
+			right := fmt.Sprintf("%s%s%s", b.Name, columnNames[q], y.Gan)
+			switch {
+			case isTianGanHe(x.Gan, y.Gan):
+				pair.GanRelations = append(pair.GanRelations, left+"合"+right)
+				score += groupGanHeScore
+			case isTianGanChong(x.Gan, y.Gan):
+				pair.GanRelations = append(pair.GanRelations, left+"冲"+right)
+				score += groupGanChongScore
+			}
+
+			left = fmt.Sprintf("%s%s%s", a.Name, columnNames[p], x.Zhi)
+			right = fmt.Sprintf("%s%s%s", b.Name, columnNames[q], y.Zhi)
+			switch {
+			case isDiZhiLiuHe(x.Zhi, y.Zhi):
+				pair.ZhiRelations = append(pair.ZhiRelations, left+"六合"+right)
+				score += groupLiuHeScore
+			case isDiZhiSanHe(x.Zhi, y.Zhi):
+				pair.ZhiRelations = append(pair.ZhiRelations, left+"三合"+right)
+				score += groupSanHeScore
+			case isDiZhiChong(x.Zhi, y.Zhi):
+				pair.ZhiRelations = append(pair.ZhiRelations, left+"冲"+right)
+				score += groupChongScore
+			case isDiZhiHai(x.Zhi, y.Zhi):
+				pair.ZhiRelations = append(pair.ZhiRelations, left+"害"+right)
+				score += groupHaiScore
+			}
+		}
+	}
+
+	return score
+}
+
+// findings 群体结论：五行缺失与偏旺、共同用神、最和谐与最冲突的组合、受众人助益或损害的成员
+func (s *GroupService) findings(result *models.GroupResult, roles []map[string]string) []string {
+	findings := []string{}
+	members := result.Members
+	n := len(members)
+
+	// 全体五行分布：平均每人每行 1.6 字，全无为缺，不足半人一字为偏少，达平均两倍为偏旺
+	scarce := map[string]bool{}
+	for _, wuxing := range wuXingList {
+		total := result.WuXingTotals[wuxing]
+		switch {
+		case total == 0:
+			scarce[wuxing] = true
+			findings = append(findings, fmt.Sprintf("全体成员八字均不见%s，团队五行缺%s", wuxing, wuxing))
+		case total*2 <= n:
+			scarce[wuxing] = true
+			findings = append(findings, fmt.Sprintf("全体成员八字%s仅%d字，%s偏少", wuxing, total, wuxing))
+		case total*5 >= n*16:
+			findings = append(findings, fmt.Sprintf("全体成员八字%s共%d字，%s偏旺", wuxing, total, wuxing))
+		}
+	}
+
+	// 共同用神
+	for _, wuxing := range wuXingList {
+		names := []string{}
+		for i, memberRoles := range roles {
+			if memberRoles[wuxing] == "用神" {
+				names = append(names, members[i].Name)
+			}
+		}
+		if len(names) < 2 || len(names)*2 < n {
+			continue
+		}
+		finding := fmt.Sprintf("%s均以%s为用神", strings.Join(names, "、"), wuxing)
+		if scarce[wuxing] {
+			finding += "，而团队" + wuxing + "不足，宜引入" + wuxing + "旺之人或从环境上补" + wuxing
+		}
+		findings = append(findings, finding)
+	}
+
+	// 最和谐与最冲突的组合
+	if len(result.Pairs) > 1 {
+		best, worst := result.Pairs[0], result.Pairs[0]
+		for _, pair := range result.Pairs[1:] {
+			if pair.Score > best.Score {
+				best = pair
+			}
+			if pair.Score < worst.Score {
+				worst = pair
+			}
+		}
+		if best.Score > 0 {
+			findings = append(findings, fmt.Sprintf("%s与%s互动最为和谐（%d分）", members[best.A].Name, members[best.B].Name, best.Score))
+		}
+		if worst.Score < 0 {
+			findings = append(findings, fmt.Sprintf("%s与%s冲突最多（%d分），合作宜多沟通", members[worst.A].Name, members[worst.B].Name, worst.Score))
+		}
+	}
+
+	// 受众人助益或损害的成员
+	helpedNames, harmedNames := []string{}, []string{}
+	for i, row := range result.Matrix {
+		helped, harmed := 0, 0
+		for j, value := range row {
+			switch {
+			case j == i:
+			case value > 0:
+				helped++
+			case value < 0:
+				harmed++
+			}
+		}
+		switch {
+		case n > 2 && helped == n-1:
+			helpedNames = append(helpedNames, members[i].Name)
+		case n > 2 && harmed == n-1:
+			harmedNames = append(harmedNames, members[i].Name)
+		}
+	}
+	if len(helpedNames) > 0 {
+		findings = append(findings, fmt.Sprintf("%s得其余成员五行相助，适合居于核心", strings.Join(helpedNames, "、")))
+	}
+	if len(harmedNames) > 0 {
+		findings = append(findings, fmt.Sprintf("%s与其余成员五行多相损，宜注意自身状态", strings.Join(harmedNames, "、")))
+	}
+
+	return findings
+}
@@ -0,0 +1,114 @@
+package services
+
+// 八卦与六十四卦的基础数据，供六爻、梅花易数共用。
+// 爻序一律自下而上，true 为阳爻。
+
+// baGua 八卦
+type baGua struct {
+	name   string
+	image  string // 卦象，如 天、泽
+	number int    // 先天数
+	wuXing string
+	lines  [3]bool      // 初、二、三爻
+	gan    [2]string    // 纳甲天干：内卦、外卦
+	zhi    [2][3]string // 纳甲地支：内卦、外卦，自下而上
+}
+
+var (
+	// 八卦（先天序：乾一、兑二、离三、震四、巽五、坎六、艮七、坤八）
+	baGuaList = []baGua{
+		{"乾", "天", 1, "金", [3]bool{true, true, true}, [2]string{"甲", "壬"}, [2][3]string{{"子", "寅", "辰"}, {"午", "申", "戌"}}},
+		{"兑", "泽", 2, "金", [3]bool{true, true, false}, [2]string{"丁", "丁"}, [2][3]string{{"巳", "卯", "丑"}, {"亥", "酉", "未"}}},
+		{"离", "火", 3, "火", [3]bool{true, false, true}, [2]string{"己", "己"}, [2][3]string{{"卯", "丑", "亥"}, {"酉", "未", "巳"}}},
+		{"震", "雷", 4, "木", [3]bool{true, false, false}, [2]string{"庚", "庚"}, [2][3]string{{"子", "寅", "辰"}, {"午", "申", "戌"}}},
+		{"巽", "风", 5, "木", [3]bool{false, true, true}, [2]string{"辛", "辛"}, [2][3]string{{"丑", "亥", "酉"}, {"未", "巳", "卯"}}},
+		{"坎", "水", 6, "水", [3]bool{false, true, false}, [2]string{"戊", "戊"}, [2][3]string{{"寅", "辰", "午"}, {"申", "戌", "子"}}},
+		{"艮", "山", 7, "土", [3]bool{false, false, true}, [2]string{"丙", "丙"}, [2][3]string{{"辰", "午", "申"}, {"戌", "子", "寅"}}},
+		{"坤", "地", 8, "土", [3]bool{false, false, false}, [2]string{"乙", "癸"}, [2][3]string{{"未", "巳", "卯"}, {"丑", "亥", "酉"}}},
+	}
+
+	// 八宫卦序：本宫、一世至五世、游魂、归魂
+	guaPalaceNames = map[string][8]string{
+		"乾": {"乾为天", "天风姤", "天山遁", "天地否", "风地观", "山地剥", "火地晋", "火天大有"},
+		"坎": {"坎为水", "水泽节", "水雷屯", "水火既济", "泽火革", "雷火丰", "地火明夷", "地水师"},
+		"艮": {"艮为山", "山火贲", "山天大畜", "山泽损", "火泽睽", "天泽履", "风泽中孚", "风山渐"},
+		"震": {"震为雷", "雷地豫", "雷水解", "雷风恒", "地风升", "水风井", "泽风大过", "泽雷随"},
+		"巽": {"巽为风", "风天小畜", "风火家人", "风雷益", "天雷无妄", "火雷噬嗑", "山雷颐", "山风蛊"},
+		"离": {"离为火", "火山旅", "火风鼎", "火水未济", "山水蒙", "风水涣", "天水讼", "天火同人"},
+		"坤": {"坤为地", "地雷复", "地泽临", "地天泰", "雷天大壮", "泽天夬", "水天需", "水地比"},
+		"兑": {"兑为泽", "泽水困", "泽地萃", "泽山咸", "水山蹇", "地山谦", "雷山小过", "雷泽归妹"},
+	}
+	guaGenerations = []string{"本宫", "一世", "二世", "三世", "四世", "五世", "游魂", "归魂"}
+
+	// 各世卦的世爻位置，应爻与世爻相隔两爻
+	guaShiPositions = []int{6, 1, 2, 3, 4, 5, 4, 3}
+)
+
+// baGuaByNumber 按先天数取卦，数以八为周，余零作八
+func baGuaByNumber(number int) baGua {
+	return baGuaList[((number-1)%8+8)%8]
+}
+
+// baGuaOf 由三爻求卦
+func baGuaOf(lines [3]bool) baGua {
+	for _, gua := range baGuaList {
+		if gua.lines == lines {
+			return gua
+		}
+	}
+	return baGuaList[0]
+}
+
+// composeGua 上下卦合成六爻
+func composeGua(upper, lower baGua) [6]bool {
+	return [6]bool{lower.lines[0], lower.lines[1], lower.lines[2], upper.lines[0], upper.lines[1], upper.lines[2]}
+}
+
+// splitGua 六爻拆为上、下卦
+func splitGua(lines [6]bool) (upper, lower baGua) {
+	return baGuaOf([3]bool{lines[3], lines[4], lines[5]}), baGuaOf([3]bool{lines[0], lines[1], lines[2]})
+}
+
+// palaceGua 某宫第 generation 卦（0 本宫 … 7 归魂）：
+// 一世至五世自初爻起逐爻变，游魂再变回四爻，归魂复以本宫为内卦
+func palaceGua(palace baGua, generation int) [6]bool {
+	lines := composeGua(palace, palace)
+	for i := 0; i < generation && i < 5; i++ {
+		lines[i] = !lines[i]
+	}
+	if generation >= 6 {
+		lines[3] = !lines[3]
+	}
+	if generation == 7 {
+		copy(lines[:3], palace.lines[:])
+	}
+	return lines
+}
+
+// guaPalace 六爻所属八宫及世次
+func guaPalace(lines [6]bool) (baGua, int) {
+	for _, palace := range baGuaList {
+		for generation := range guaGenerations {
+			if palaceGua(palace, generation) == lines {
+				return palace, generation
+			}
+		}
+	}
+	return baGuaList[0], 0
+}
+
+// guaName 六十四卦卦名
+func guaName(lines [6]bool) string {
+	palace, generation := guaPalace(lines)
+	return guaPalaceNames[palace.name][generation]
+}
+
+// changeGua 动爻阴阳互变
+func changeGua(lines [6]bool, moving [6]bool) [6]bool {
+	for i := range lines {
+		if moving[i] {
+			lines[i] = !lines[i]
+		}
+	}
+	return lines
+}
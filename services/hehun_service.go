@@ -0,0 +1,273 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"strings"
+)
+
+// HeHunService 合婚服务
+//
+// 双方各自排盘后，从日干相合、夫妻宫（日支）关系、喜用神互补、年柱纳音生克
+// 及生肖（年支）合冲五方面逐项评分，每项给出得分依据，汇总为总分与婚配等级。
+type HeHunService struct {
+	profile           *SchoolProfile
+	baziService       *BaziService
+	xiYongShenService *XiYongShenService
+	naYinService      *NaYinService
+	zhuXingService    *ZhuXingService
+}
+
+var (
+	// 地支生肖
+	shengXiao = map[string]string{
+		"子": "鼠", "丑": "牛", "寅": "虎", "卯": "兔", "辰": "龙", "巳": "蛇",
+		"午": "马", "未": "羊", "申": "猴", "酉": "鸡", "戌": "狗", "亥": "猪",
+	}
+
+	// 夫妻宫关系断语
+	heHunPalaceNotes = map[string]string{
+		"六合":  "感情和睦",
+		"三合":  "相处融洽",
+		"相同":  "性情相似，宜互相包容",
+		"无刑冲": "平稳无碍",
+		"六害":  "易生嫌隙",
+		"六冲":  "婚姻多波折",
+	}
+
+	// 生肖关系断语
+	heHunZodiacNotes = map[string]string{
+		"六合":  "上等相配",
+		"三合":  "相配和顺",
+		"相同":  "比和平配",
+		"无刑冲": "平配",
+		"六害":  "宜多体谅",
+		"六冲":  "古称大忌",
+	}
+)
+
+const (
+	heHunRiGanMax  = 20 // 日干相合
+	heHunPalaceMax = 20 // 夫妻宫
+	heHunXiYongMax = 24 // 喜用神互补（双方各半）
+	heHunNaYinMax  = 16 // 年柱纳音
+	heHunZodiacMax = 20 // 生肖
+)
+
+func NewHeHunService() *HeHunService {
+	return newHeHunService(defaultSchoolProfile())
+}
+
+// newHeHunService 按流派创建合婚服务，排盘及喜用神均采用该流派
+func newHeHunService(profile *SchoolProfile) *HeHunService {
+	return &HeHunService{
+		profile:           profile,
+		baziService:       newBaziService(profile),
+		xiYongShenService: newXiYongShenService(profile),
+		naYinService:      NewNaYinService(),
+		zhuXingService:    NewZhuXingService(),
+	}
+}
+
+// withProfile 返回绑定指定流派的服务实例，流派相同时返回自身
+func (s *HeHunService) withProfile(profile *SchoolProfile) *HeHunService {
+	if profile == s.profile {
+		return s
+	}
+	return newHeHunService(profile)
+}
+
+// Evaluate 合婚评估
+//
+// 满分 100：日干 20、夫妻宫 20、喜用互补 24、纳音 16、生肖 20。
+// 总分 80 以上为上等婚，60 以上为中等婚，其余为下等婚。
+func (s *HeHunService) Evaluate(req models.HeHunRequest, opts CalcOptions) (*models.HeHunResult, error) {
+	s = s.withProfile(opts.profile())
+	chartOpts := CalcOptions{Profile: opts.Profile}
+
+	personA, err := s.baziService.CalculateBazi(req.PersonA, chartOpts)
+	if err != nil {
+		return &models.HeHunResult{Error: fmt.Sprintf("%s排盘失败: %s", req.PersonA.Name, err.Error())}, err
+	}
+	personB, err := s.baziService.CalculateBazi(req.PersonB, chartOpts)
+	if err != nil {
+		return &models.HeHunResult{Error: fmt.Sprintf("%s排盘失败: %s", req.PersonB.Name, err.Error())}, err
+	}
+
+	a, b := personA.Bazi, personB.Bazi
+	nameA, nameB := req.PersonA.Name, req.PersonB.Name
+
+	result := &models.HeHunResult{
+		PersonA: personA,
+		PersonB: personB,
+		Items: []models.HeHunItem{
+			s.riGanItem(a[2], b[2]),
+			s.palaceItem(a[2].Zhi, b[2].Zhi),
+			s.xiYongItem(nameA, a, nameB, b, chartOpts),
+			s.naYinItem(a[0].NaYin, b[0].NaYin),
+			s.zodiacItem(a[0].Zhi, b[0].Zhi),
+		},
+		School: s.profile.ID(),
+	}
+
+	for _, item := range result.Items {
+		result.Score += item.Score
+		result.MaxScore += item.MaxScore
+	}
+	result.Level = s.level(result.Score)
+	result.Summary = s.summarize(result)
+
+	return result, nil
+}
+
+// riGanItem 日干：五合最佳，相生次之，比和平，相克减分，相冲最差
+func (s *HeHunService) riGanItem(a, b models.BaziColumn) models.HeHunItem {
+	item := models.HeHunItem{Aspect: "日干", MaxScore: heHunRiGanMax, Reasons: []string{}}
+
+	switch {
+	case isTianGanHe(a.Gan, b.Gan):
+		item.Score = 20
+		item.Reasons = append(item.Reasons, fmt.Sprintf("日干%s%s五合，日主相合，情投意合", a.Gan, b.Gan))
+	case isTianGanChong(a.Gan, b.Gan):
+		item.Score = 0
+		item.Reasons = append(item.Reasons, fmt.Sprintf("日干%s%s相冲，性情相左，易生争执", a.Gan, b.Gan))
+	case a.GanWuXing == b.GanWuXing:
+		item.Score = 12
+		item.Reasons = append(item.Reasons, fmt.Sprintf("日干%s%s同属%s，比和，志趣相近", a.Gan, b.Gan, a.GanWuXing))
+	case s.zhuXingService.isShengRelation(a.GanWuXing, b.GanWuXing) || s.zhuXingService.isShengRelation(b.GanWuXing, a.GanWuXing):
+		item.Score = 15
+		item.Reasons = append(item.Reasons, fmt.Sprintf("日干%s(%s)%s(%s)相生，彼此扶持", a.Gan, a.GanWuXing, b.Gan, b.GanWuXing))
+	default:
+		item.Score = 6
+		item.Reasons = append(item.Reasons, fmt.Sprintf("日干%s(%s)%s(%s)相克，一方易受压制", a.Gan, a.GanWuXing, b.Gan, b.GanWuXing))
+	}
+
+	return item
+}
+
+// palaceItem 夫妻宫：双方日支的合冲害
+func (s *HeHunService) palaceItem(a, b string) models.HeHunItem {
+	item := models.HeHunItem{Aspect: "夫妻宫", MaxScore: heHunPalaceMax, Reasons: []string{}}
+
+	relation, score := s.branchRelation(a, b)
+	item.Score = score
+	item.Reasons = append(item.Reasons, fmt.Sprintf("夫妻宫%s%s%s，%s", a, b, relation, heHunPalaceNotes[relation]))
+
+	return item
+}
+
+// zodiacItem 生肖：双方年支的合冲害
+func (s *HeHunService) zodiacItem(a, b string) models.HeHunItem {
+	item := models.HeHunItem{Aspect: "生肖", MaxScore: heHunZodiacMax, Reasons: []string{}}
+
+	relation, score := s.branchRelation(a, b)
+	item.Score = score
+	item.Reasons = append(item.Reasons, fmt.Sprintf("生肖%s(%s)%s(%s)%s，%s", shengXiao[a], a, shengXiao[b], b, relation, heHunZodiacNotes[relation]))
+
+	return item
+}
+
+// branchRelation 两地支关系及得分（满分 20）：六合 20、三合 16、无刑冲 12、相同 10、六害 5、六冲 0
+func (s *HeHunService) branchRelation(a, b string) (string, int) {
+	switch {
+	case isDiZhiLiuHe(a, b):
+		return "六合", 20
+	case isDiZhiSanHe(a, b):
+		return "三合", 16
+	case isDiZhiChong(a, b):
+		return "六冲", 0
+	case isDiZhiHai(a, b):
+		return "六害", 5
+	case a == b:
+		return "相同", 10
+	default:
+		return "无刑冲", 12
+	}
+}
+
+// xiYongItem 喜用神互补：对方八字中为我用神、喜神的干支越多，越能补我所需
+func (s *HeHunService) xiYongItem(nameA string, a []models.BaziColumn, nameB string, b []models.BaziColumn, opts CalcOptions) models.HeHunItem {
+	item := models.HeHunItem{Aspect: "喜用互补", MaxScore: heHunXiYongMax, Reasons: []string{}}
+
+	xiYongA := s.xiYongShenService.Calculate(a, opts)
+	xiYongB := s.xiYongShenService.Calculate(b, opts)
+
+	scoreA, reasonA := s.supply(nameA, xiYongA, nameB, b)
+	scoreB, reasonB := s.supply(nameB, xiYongB, nameA, a)
+	item.Score = scoreA + scoreB
+	item.Reasons = append(item.Reasons, reasonA, reasonB)
+
+	return item
+}
+
+// supply 对方八字对我喜用神的补益（满分 12）：喜用字 4 个以上 12、2-3 个 8、1 个 4、无 0
+func (s *HeHunService) supply(name string, xiYong *models.XiYongShenResult, otherName string, other []models.BaziColumn) (int, string) {
+	favorable, _ := rolesInChart(xiYong.WuXingRoles, other)
+
+	score := 0
+	switch count := len(favorable); {
+	case count >= 4:
+		score = 12
+	case count >= 2:
+		score = 8
+	case count == 1:
+		score = 4
+	}
+
+	need := fmt.Sprintf("%s日主%s%s，喜用%s", name, xiYong.RiZhu, xiYong.RiZhuStrength, xiYong.XiYongShen)
+	if len(favorable) == 0 {
+		return score, fmt.Sprintf("%s，%s八字不见喜用之字，难补所需", need, otherName)
+	}
+	return score, fmt.Sprintf("%s，%s八字见%s共%d字，能补所需", need, otherName, strings.Join(favorable, ""), len(favorable))
+}
+
+// naYinItem 年柱纳音：相生最佳，比和次之，相克视受克特例而定
+func (s *HeHunService) naYinItem(a, b string) models.HeHunItem {
+	item := models.HeHunItem{Aspect: "纳音", MaxScore: heHunNaYinMax, Reasons: []string{}}
+
+	relation, verdict, description := s.naYinService.Relate(a, b)
+	if relation == "我克" {
+		// 以受克一方为我，受克特例按受克方判断
+		relation, verdict, description = s.naYinService.Relate(b, a)
+	}
+
+	switch {
+	case relation == "生我" || relation == "我生":
+		item.Score = 16
+		description = fmt.Sprintf("%s(%s)与%s(%s)相生，互为助益", a, naYinWuXing(a), b, naYinWuXing(b))
+	case relation == "比和":
+		item.Score = 12
+	case verdict == "吉":
+		item.Score = 12
+	case verdict == "平":
+		item.Score = 8
+	case relation != "":
+		item.Score = 4
+	}
+	if description != "" {
+		item.Reasons = append(item.Reasons, "年柱纳音"+description)
+	}
+
+	return item
+}
+
+// level 婚配等级
+func (s *HeHunService) level(score int) string {
+	switch {
+	case score >= 80:
+		return "上等婚"
+	case score >= 60:
+		return "中等婚"
+	default:
+		return "下等婚"
+	}
+}
+
+// summarize 汇总各项得分
+func (s *HeHunService) summarize(result *models.HeHunResult) string {
+	parts := []string{}
+	for _, item := range result.Items {
+		parts = append(parts, fmt.Sprintf("%s%d/%d", item.Aspect, item.Score, item.MaxScore))
+	}
+	return fmt.Sprintf("合婚总分%d/%d，%s（%s）", result.Score, result.MaxScore, result.Level, strings.Join(parts, "，"))
+}
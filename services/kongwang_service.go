@@ -1,5 +1,10 @@
 package services
 
+import (
+	"auspire/models"
+	"fmt"
+)
+
 // KongWangService 空亡服务
 type KongWangService struct{}
 
@@ -22,6 +27,21 @@ var (
 		"壬子": {"寅", "卯"}, "壬戌": {"子", "丑"}, "癸酉": {"戌", "亥"}, "癸未": {"申", "酉"},
 		"癸巳": {"午", "未"}, "癸卯": {"辰", "巳"}, "癸丑": {"寅", "卯"}, "癸亥": {"子", "丑"},
 	}
+
+	// 截路空亡：以日干查时支（五鼠遁中壬癸所临之时）
+	// 歌诀：甲己申酉最为愁，乙庚午未不须求，丙辛辰巳何劳问，丁壬寅卯一场空，戊癸子丑高堂坐
+	jieLuKongWangData = map[string][]string{
+		"甲": {"申", "酉"}, "己": {"申", "酉"},
+		"乙": {"午", "未"}, "庚": {"午", "未"},
+		"丙": {"辰", "巳"}, "辛": {"辰", "巳"},
+		"丁": {"寅", "卯"}, "壬": {"寅", "卯"},
+		"戊": {"子", "丑"}, "癸": {"子", "丑"},
+	}
+
+	// 四大空亡：甲子、甲午旬纳音无水，甲寅、甲申旬纳音无金
+	siDaKongWangData = map[string]string{
+		"甲子": "水", "甲午": "水", "甲寅": "金", "甲申": "金",
+	}
 )
 
 func NewKongWangService() *KongWangService {
@@ -53,4 +73,103 @@ func (s *KongWangService) GetKongWangZhi(dayGanZhi string) []string {
 // GetAllKongWang 获取所有空亡数据
 func (s *KongWangService) GetAllKongWang() map[string][]string {
 	return kongWangData
-}
\ No newline at end of file
+}
+
+// Analyze 空亡综合分析：日空、年空及截路空亡、四大空亡
+//
+// 需在各柱纳音计算之后调用。
+func (s *KongWangService) Analyze(bazi []models.BaziColumn) *models.KongWangAnalysis {
+	columnNames := []string{"年柱", "月柱", "日柱", "时柱"}
+	result := &models.KongWangAnalysis{
+		RiKong:   s.GetKongWangZhi(bazi[2].Gan + bazi[2].Zhi),
+		NianKong: s.GetKongWangZhi(bazi[0].Gan + bazi[0].Zhi),
+		Variants: []models.KongWangVariant{},
+	}
+
+	// 截路空亡：时支逢日干所忌之支
+	for _, zhi := range jieLuKongWangData[bazi[2].Gan] {
+		if bazi[3].Zhi == zhi {
+			result.Variants = append(result.Variants, models.KongWangVariant{
+				Name:        "截路空亡",
+				Pillar:      "时柱",
+				Basis:       "日干",
+				Description: fmt.Sprintf("%s日见%s时，主做事多阻、进退两难", bazi[2].Gan, zhi),
+			})
+		}
+	}
+
+	// 四大空亡：以年柱、日柱所在旬查他柱纳音
+	for _, base := range []int{0, 2} {
+		xun := xunShou(bazi[base].Gan, bazi[base].Zhi)
+		missing, exists := siDaKongWangData[xun]
+		if !exists {
+			continue
+		}
+		for i, column := range bazi {
+			if i == base || naYinWuXing(column.NaYin) != missing {
+				continue
+			}
+			result.Variants = append(result.Variants, models.KongWangVariant{
+				Name:        "四大空亡",
+				Pillar:      columnNames[i],
+				Basis:       columnNames[base],
+				Description: fmt.Sprintf("%s在%s旬，旬中纳音无%s，%s纳音%s犯之，主根基不稳、多成多败", columnNames[base], xun, missing, columnNames[i], column.NaYin),
+			})
+		}
+	}
+
+	return result
+}
+
+// MarkPeriod 标注大运、流年地支的空亡情况
+//
+// 返回岁运地支所落的旬空（日空、年空），以及对原局落空之柱的作用：
+// 岁运地支与落空之支相同为填实，六冲落空之支为冲空，二者皆主空而不空、事情应验。
+func (s *KongWangService) MarkPeriod(bazi []models.BaziColumn, periodZhi string) ([]string, []models.KongWangEvent) {
+	columnNames := []string{"年柱", "月柱", "日柱", "时柱"}
+	riKongZhi := bazi[2].Gan + bazi[2].Zhi
+	nianKongZhi := bazi[0].Gan + bazi[0].Zhi
+
+	var kongWang []string
+	if s.Calculate(riKongZhi, periodZhi) {
+		kongWang = append(kongWang, "日空")
+	}
+	if s.Calculate(nianKongZhi, periodZhi) {
+		kongWang = append(kongWang, "年空")
+	}
+
+	var events []models.KongWangEvent
+	for i, column := range bazi {
+		if !s.Calculate(riKongZhi, column.Zhi) && !s.Calculate(nianKongZhi, column.Zhi) {
+			continue
+		}
+		switch {
+		case periodZhi == column.Zhi:
+			events = append(events, models.KongWangEvent{
+				Type:        "填实",
+				Pillar:      columnNames[i],
+				Zhi:         column.Zhi,
+				Description: fmt.Sprintf("岁运逢%s填实%s空亡，空而不空，所主之事应验", periodZhi, columnNames[i]),
+			})
+		case isDiZhiChong(periodZhi, column.Zhi):
+			events = append(events, models.KongWangEvent{
+				Type:        "冲空",
+				Pillar:      columnNames[i],
+				Zhi:         column.Zhi,
+				Description: fmt.Sprintf("岁运%s冲%s空亡之%s，冲空则不空，所主之事发动", periodZhi, columnNames[i], column.Zhi),
+			})
+		}
+	}
+
+	return kongWang, events
+}
+
+// xunShou 干支所在旬的旬首，如 丙寅 在甲子旬
+func xunShou(gan, zhi string) string {
+	index := jiaZiIndex(gan, zhi)
+	if index < 0 {
+		return ""
+	}
+	head := index - index%10
+	return tianGan[0] + diZhi[head%12]
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"strings"
+)
+
+// LiuQinService 六亲服务
+//
+// 以十神定六亲，男女命取法不同：男命以正财为妻、七杀为子、正官为女；
+// 女命以正官为夫、伤官为子、食神为女。天干与地支藏干均按其十神标注六亲，
+// 并汇总各主要亲属的星曜所在宫位及力量。
+type LiuQinService struct{}
+
+var (
+	// 十神对应六亲（按性别）
+	liuQinData = map[string]map[string]string{
+		"男": {
+			"比肩": "兄弟", "劫财": "姐妹", "食神": "孙女", "伤官": "祖母、孙子",
+			"偏财": "父亲", "正财": "妻子", "七杀": "儿子", "正官": "女儿",
+			"偏印": "祖父、继母", "正印": "母亲",
+		},
+		"女": {
+			"比肩": "姐妹", "劫财": "兄弟", "食神": "女儿", "伤官": "儿子",
+			"偏财": "父亲", "正财": "婆婆", "七杀": "偏夫", "正官": "丈夫",
+			"偏印": "祖父、继母", "正印": "母亲",
+		},
+	}
+
+	// 六亲汇总的主要亲属及其星（按性别，按展示顺序）
+	liuQinRelatives = map[string][][2]string{
+		"男": {{"父亲", "偏财"}, {"母亲", "正印"}, {"兄弟", "比肩"}, {"姐妹", "劫财"}, {"妻子", "正财"}, {"儿子", "七杀"}, {"女儿", "正官"}},
+		"女": {{"父亲", "偏财"}, {"母亲", "正印"}, {"兄弟", "劫财"}, {"姐妹", "比肩"}, {"丈夫", "正官"}, {"儿子", "伤官"}, {"女儿", "食神"}},
+	}
+
+	// 亲属本应所居的宫位（柱序号）
+	liuQinHomePalace = map[string]int{
+		"父亲": 0, "母亲": 0, "兄弟": 1, "姐妹": 1,
+		"妻子": 2, "丈夫": 2, "儿子": 3, "女儿": 3,
+	}
+
+	// 五行论六亲所用的生克判断（ZhuXingService 无状态，全包共用一个实例）
+	liuQinZhuXing = NewZhuXingService()
+)
+
+const (
+	// 天干透出计分，藏干按其力量占比计分
+	liuQinStemWeight = 100
+	// 六亲星五行当令另加分
+	liuQinSeasonBonus = 50
+)
+
+// liuQinOf 以五行论六亲（六爻以宫五行、六壬以日干五行为我）：同我兄弟，生我父母，我生子孙，克我官鬼，我克妻财
+func liuQinOf(me, other string) string {
+	switch {
+	case me == other:
+		return "兄弟"
+	case liuQinZhuXing.isShengRelation(other, me):
+		return "父母"
+	case liuQinZhuXing.isShengRelation(me, other):
+		return "子孙"
+	case liuQinZhuXing.isKeRelation(other, me):
+		return "官鬼"
+	default:
+		return "妻财"
+	}
+}
+
+func NewLiuQinService() *LiuQinService {
+	return &LiuQinService{}
+}
+
+// Relative 查询十神在给定性别下对应的六亲
+func (s *LiuQinService) Relative(shiShen, gender string) string {
+	return liuQinData[gender][shiShen]
+}
+
+// Annotate 按性别为各柱天干及藏干标注六亲，日干标为本人
+func (s *LiuQinService) Annotate(bazi []models.BaziColumn, gender string) {
+	for i := range bazi {
+		if i == 2 {
+			bazi[i].LiuQin = "本人"
+		} else {
+			bazi[i].LiuQin = s.Relative(bazi[i].ZhuXing, gender)
+		}
+		for j := range bazi[i].CangGan {
+			bazi[i].CangGan[j].LiuQin = s.Relative(bazi[i].CangGan[j].ShiShen, gender)
+		}
+	}
+}
+
+// Summarize 汇总主要亲属的星曜、所在宫位及力量
+//
+// 力量以天干透出每处计 100、藏干按其力量占比计分，六亲星五行得月令另加 50：
+// 0 为不现，不足 60 为弱，不足 160 为中，其余为旺。
+func (s *LiuQinService) Summarize(bazi []models.BaziColumn, gender string) []models.LiuQinEntry {
+	columnNames := []string{"年柱", "月柱", "日柱", "时柱"}
+	result := []models.LiuQinEntry{}
+
+	for _, relative := range liuQinRelatives[gender] {
+		name, star := relative[0], relative[1]
+		entry := models.LiuQinEntry{
+			Relative:  name,
+			Star:      star,
+			Positions: []models.LiuQinPosition{},
+		}
+
+		starWuXing := ""
+		for i, column := range bazi {
+			if i != 2 && column.ZhuXing == star {
+				entry.Positions = append(entry.Positions, models.LiuQinPosition{
+					Pillar: columnNames[i],
+					Palace: palaceNames[i],
+					Gan:    column.Gan,
+					Source: "天干",
+				})
+				entry.Score += liuQinStemWeight
+				starWuXing = column.GanWuXing
+			}
+			for _, item := range column.CangGan {
+				if item.ShiShen == star {
+					entry.Positions = append(entry.Positions, models.LiuQinPosition{
+						Pillar: columnNames[i],
+						Palace: palaceNames[i],
+						Gan:    item.Gan,
+						Source: "藏干" + item.Role,
+					})
+					entry.Score += item.Weight
+					starWuXing = item.WuXing
+				}
+			}
+		}
+
+		deLing := starWuXing != "" && starWuXing == bazi[1].ZhiWuXing
+		if deLing {
+			entry.Score += liuQinSeasonBonus
+		}
+		entry.Strength = s.strengthLevel(entry.Score)
+		entry.Description = s.describe(entry, deLing)
+		result = append(result, entry)
+	}
+
+	return result
+}
+
+// strengthLevel 六亲星力量分级
+func (s *LiuQinService) strengthLevel(score int) string {
+	switch {
+	case score == 0:
+		return "不现"
+	case score < 60:
+		return "弱"
+	case score < 160:
+		return "中"
+	default:
+		return "旺"
+	}
+}
+
+// describe 生成六亲星的简要说明
+func (s *LiuQinService) describe(entry models.LiuQinEntry, deLing bool) string {
+	if len(entry.Positions) == 0 {
+		return fmt.Sprintf("%s星%s不现于四柱，与%s缘分较浅或助力有限", entry.Relative, entry.Star, entry.Relative)
+	}
+
+	places := []string{}
+	inHome := false
+	for _, position := range entry.Positions {
+		places = append(places, position.Pillar+position.Source+position.Gan)
+		if home, exists := liuQinHomePalace[entry.Relative]; exists && position.Palace == palaceNames[home] {
+			inHome = true
+		}
+	}
+
+	description := fmt.Sprintf("%s星%s见于%s，力量%s", entry.Relative, entry.Star, strings.Join(places, "、"), entry.Strength)
+	if deLing {
+		description += "，得月令"
+	}
+	if home, exists := liuQinHomePalace[entry.Relative]; exists {
+		if inHome {
+			description += "，星坐" + palaceNames[home] + "，星宫相应"
+		} else {
+			description += "，未坐" + palaceNames[home]
+		}
+	}
+	return description
+}
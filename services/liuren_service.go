@@ -0,0 +1,381 @@
+package services
+
+import (
+	"auspire/models"
+	"auspire/services/solarterm"
+	"fmt"
+	"time"
+)
+
+// LiuRenService 大六壬排盘服务
+//
+// 以中气定月将，月将加占时得天地盘；由日干寄宫与日支起四课，按九宗门
+// （贼克、比用、涉害、遥克、昴星、别责、八专、伏吟、返吟）发三传，
+// 再依日干及昼夜起贵人，顺逆布十二天将。涉害以孟仲季深浅取之。
+type LiuRenService struct {
+	baziService     *BaziService
+	kongWangService *KongWangService
+	zhuXingService  *ZhuXingService
+}
+
+// liuRenLesson 四课之一
+type liuRenLesson struct {
+	lower    string // 下神：一课为日干，其余为地支
+	lowerZhi string // 下神所在地盘之位（日干取寄宫）
+	upper    string // 上神
+}
+
+var (
+	// 月将：中气后换将，雨水后亥将……大寒后子将
+	liuRenYueJiang = map[string]string{
+		"雨水": "亥", "春分": "戌", "谷雨": "酉", "小满": "申", "夏至": "未", "大暑": "午",
+		"处暑": "巳", "秋分": "辰", "霜降": "卯", "小雪": "寅", "冬至": "丑", "大寒": "子",
+	}
+
+	// 十二月将名
+	liuRenJiangNames = map[string]string{
+		"子": "神后", "丑": "大吉", "寅": "功曹", "卯": "太冲", "辰": "天罡", "巳": "太乙",
+		"午": "胜光", "未": "小吉", "申": "传送", "酉": "从魁", "戌": "河魁", "亥": "登明",
+	}
+
+	// 日干寄宫
+	liuRenJiGong = map[string]string{
+		"甲": "寅", "乙": "辰", "丙": "巳", "丁": "未", "戊": "巳",
+		"己": "未", "庚": "申", "辛": "戌", "壬": "亥", "癸": "丑",
+	}
+
+	// 十二天将（自贵人起）
+	liuRenGenerals = []string{"贵人", "螣蛇", "朱雀", "六合", "勾陈", "青龙", "天空", "白虎", "太常", "玄武", "太阴", "天后"}
+
+	// 四孟、四仲（涉害取孟仲）
+	liuRenMeng  = map[string]bool{"寅": true, "申": true, "巳": true, "亥": true}
+	liuRenZhong = map[string]bool{"子": true, "午": true, "卯": true, "酉": true}
+
+	liuRenLessonNames       = []string{"一课", "二课", "三课", "四课"}
+	liuRenTransmissionNames = []string{"初传", "中传", "末传"}
+)
+
+func NewLiuRenService() *LiuRenService {
+	return &LiuRenService{
+		baziService:     NewBaziService(),
+		kongWangService: NewKongWangService(),
+		zhuXingService:  NewZhuXingService(),
+	}
+}
+
+// Calculate 大六壬排盘
+//
+// 昼夜以占时定：卯至申为昼，酉至寅为夜；贵人临地盘亥至辰顺布天将，临巳至戌逆布。
+func (s *LiuRenService) Calculate(req models.LiuRenRequest) (*models.LiuRenResult, error) {
+	bazi, err := s.baziService.calculateBaziColumns(req.Date, req.Time)
+	if err != nil {
+		return &models.LiuRenResult{Date: req.Date, Time: req.Time, Error: err.Error()}, err
+	}
+	date, _ := time.Parse("2006-01-02", req.Date)
+	day, hour := bazi[2], bazi[3]
+
+	zhongQi, _ := solarterm.PrevZhongQi(date)
+	yueJiang := liuRenYueJiang[zhongQi]
+
+	// 天地盘：月将加占时，heaven[地支] 为其上所临天盘之神
+	shift := indexOf(diZhi, yueJiang) - indexOf(diZhi, hour.Zhi)
+	heaven := map[string]string{}
+	earth := map[string]string{}
+	for i, zhi := range diZhi {
+		heaven[zhi] = diZhi[((i+shift)%12+12)%12]
+		earth[heaven[zhi]] = zhi
+	}
+
+	// 四课
+	jiGong := liuRenJiGong[day.Gan]
+	first := heaven[jiGong]
+	third := heaven[day.Zhi]
+	lessons := []liuRenLesson{
+		{day.Gan, jiGong, first},
+		{first, first, heaven[first]},
+		{day.Zhi, day.Zhi, third},
+		{third, third, heaven[third]},
+	}
+
+	// 贵人与十二天将：昼夜贵人沿用神煞的天乙贵人表，前者为昼贵、后者为夜贵。
+	// 唯壬癸日六壬依「壬癸蛇兔」取昼巳夜卯，与四柱、紫微的卯前巳后相反。
+	dayTime := indexOf(diZhi, hour.Zhi) >= 3 && indexOf(diZhi, hour.Zhi) <= 8
+	guiRenPair := shenShaTable("天乙贵人")[day.Gan]
+	zhouGui, yeGui := guiRenPair[0], guiRenPair[1]
+	if day.Gan == "壬" || day.Gan == "癸" {
+		zhouGui, yeGui = yeGui, zhouGui
+	}
+	guiRen := yeGui
+	if dayTime {
+		guiRen = zhouGui
+	}
+	direction := 1
+	if position := indexOf(diZhi, earth[guiRen]); position >= 5 && position <= 10 {
+		direction = -1
+	}
+	generals := map[string]string{}
+	for k, general := range liuRenGenerals {
+		generals[diZhi[((indexOf(diZhi, guiRen)+direction*k)%12+12)%12]] = general
+	}
+
+	method, description, chuan := s.transmit(day.Gan, day.Zhi, lessons, heaven, yueJiang == hour.Zhi, isDiZhiChong(yueJiang, hour.Zhi))
+
+	result := &models.LiuRenResult{
+		Date:          req.Date,
+		Time:          req.Time,
+		Bazi:          []string{bazi[0].Gan + bazi[0].Zhi, bazi[1].Gan + bazi[1].Zhi, day.Gan + day.Zhi, hour.Gan + hour.Zhi},
+		ZhongQi:       zhongQi,
+		YueJiang:      yueJiang + liuRenJiangNames[yueJiang],
+		DayNight:      "夜",
+		GuiRen:        guiRen,
+		Direction:     "顺",
+		Plate:         []models.LiuRenPosition{},
+		Lessons:       []models.LiuRenLesson{},
+		Method:        method,
+		Description:   description,
+		Transmissions: []models.LiuRenTransmission{},
+		XunKong:       s.kongWangService.GetKongWangZhi(day.Gan + day.Zhi),
+	}
+	if dayTime {
+		result.DayNight = "昼"
+	}
+	if direction < 0 {
+		result.Direction = "逆"
+	}
+
+	for _, zhi := range diZhi {
+		result.Plate = append(result.Plate, models.LiuRenPosition{
+			Earth:   zhi,
+			Heaven:  heaven[zhi],
+			General: generals[heaven[zhi]],
+		})
+	}
+	for i, lesson := range lessons {
+		result.Lessons = append(result.Lessons, models.LiuRenLesson{
+			Name:     liuRenLessonNames[i],
+			Upper:    lesson.upper,
+			Lower:    lesson.lower,
+			General:  generals[lesson.upper],
+			Relation: s.lessonRelation(lesson),
+		})
+	}
+	for i, zhi := range chuan {
+		result.Transmissions = append(result.Transmissions, models.LiuRenTransmission{
+			Name:    liuRenTransmissionNames[i],
+			Zhi:     zhi,
+			DunGan:  s.dunGan(day.Gan, day.Zhi, zhi),
+			LiuQin:  liuQinOf(tianGanWuXing[day.Gan], diZhiWuXing[zhi]),
+			General: generals[zhi],
+		})
+	}
+
+	return result, nil
+}
+
+// transmit 按九宗门发三传
+//
+// 先伏吟；有克者取克（贼克、比用、涉害，月将冲时为返吟）；无克者依次为八专、返吟、
+// 遥克、昴星（四课全备）、别责（四课不全）。除另有取法者外，中传为初传上神，末传为中传上神。
+func (s *LiuRenService) transmit(dayGan, dayZhi string, lessons []liuRenLesson, heaven map[string]string, fuYin, fanYin bool) (string, string, [3]string) {
+	yangDay := indexOf(tianGan, dayGan)%2 == 0
+	ganShang, zhiShang := lessons[0].upper, lessons[2].upper
+
+	// 不备之课：下神之位与上神皆同者只算一课
+	distinct := []liuRenLesson{}
+	seen := map[string]bool{}
+	for _, lesson := range lessons {
+		if key := lesson.lowerZhi + lesson.upper; !seen[key] {
+			seen[key] = true
+			distinct = append(distinct, lesson)
+		}
+	}
+	var zei, ke []liuRenLesson
+	for _, lesson := range distinct {
+		switch s.lessonRelation(lesson) {
+		case "下贼上":
+			zei = append(zei, lesson)
+		case "上克下":
+			ke = append(ke, lesson)
+		}
+	}
+	candidates := zei
+	if len(candidates) == 0 {
+		candidates = ke
+	}
+
+	if fuYin {
+		first := zhiShang
+		description := "月将加时，天地盘不动为伏吟"
+		switch {
+		case len(candidates) > 0:
+			first = candidates[0].upper
+			description += "，有克取克为初传"
+		case yangDay:
+			first = ganShang
+			description += "，阳日无克取干上神为初传"
+		default:
+			description += "，阴日无克取支上神为初传"
+		}
+		description += "，递取所刑为中末传"
+		middle := diZhiXing[first]
+		if middle == first {
+			middle = ganShang
+			if first == ganShang {
+				middle = zhiShang
+			}
+			description += "；初传自刑，改取干支另一上神为中传"
+		}
+		last := diZhiXing[middle]
+		if last == middle {
+			last = diZhi[(indexOf(diZhi, middle)+6)%12]
+			description += "；中传自刑，取其冲为末传"
+		}
+		return "伏吟", description, [3]string{first, middle, last}
+	}
+
+	if len(candidates) > 0 {
+		first, method, description := s.choose(candidates, yangDay, ganShang, zhiShang)
+		if len(zei) == 1 {
+			description = "四课仅一课下贼上，取为初传（重审）"
+		} else if len(zei) == 0 && len(ke) == 1 {
+			description = "四课无下贼上，仅一课上克下，取为初传（元首）"
+		}
+		if fanYin {
+			method, description = "返吟", "月将冲占时，天地盘相冲为返吟；"+description
+		}
+		return method, description, [3]string{first, heaven[first], heaven[heaven[first]]}
+	}
+
+	if lessons[0].lowerZhi == dayZhi {
+		if yangDay {
+			return "八专", "干支同位，四课无克，阳日取干上神顺数三位为初传，中末皆取干上神",
+				[3]string{diZhi[(indexOf(diZhi, ganShang)+2)%12], ganShang, ganShang}
+		}
+		return "八专", "干支同位，四课无克，阴日取第四课上神逆数三位为初传，中末皆取干上神",
+			[3]string{diZhi[(indexOf(diZhi, lessons[3].upper)+10)%12], ganShang, ganShang}
+	}
+
+	if fanYin {
+		return "返吟", "天地盘相冲而四课无克，取日支驿马为初传，支上神为中传，干上神为末传（井栏射）",
+			[3]string{shenShaTable("驿马")[diZhiSanHe[dayZhi]][0], zhiShang, ganShang}
+	}
+
+	// 遥克：二三四课上神与日干相克，神克日为先，日克神次之
+	var shenKe, riKe []liuRenLesson
+	for _, lesson := range lessons[1:] {
+		switch {
+		case s.zhuXingService.isKeRelation(diZhiWuXing[lesson.upper], tianGanWuXing[dayGan]):
+			shenKe = append(shenKe, lesson)
+		case s.zhuXingService.isKeRelation(tianGanWuXing[dayGan], diZhiWuXing[lesson.upper]):
+			riKe = append(riKe, lesson)
+		}
+	}
+	if len(shenKe) > 0 || len(riKe) > 0 {
+		description := "四课无克，取上神遥克日干者为初传（蒿矢）"
+		if len(shenKe) == 0 {
+			shenKe = riKe
+			description = "四课无克亦无神克日，取日干遥克之上神为初传（弹射）"
+		}
+		first, _, _ := s.choose(shenKe, yangDay, ganShang, zhiShang)
+		return "遥克", description, [3]string{first, heaven[first], heaven[heaven[first]]}
+	}
+
+	if len(distinct) == 4 {
+		if yangDay {
+			return "昴星", "四课全备无克无遥，阳日取地盘酉上神为初传，支上神为中传，干上神为末传",
+				[3]string{heaven["酉"], zhiShang, ganShang}
+		}
+		first := ""
+		for zhi, over := range heaven {
+			if over == "酉" {
+				first = zhi
+			}
+		}
+		return "昴星", "四课全备无克无遥，阴日取天盘酉下神为初传，干上神为中传，支上神为末传",
+			[3]string{first, ganShang, zhiShang}
+	}
+
+	if yangDay {
+		first := heaven[liuRenJiGong[tianGanHe[dayGan]]]
+		return "别责", "四课不备无克无遥，阳日取干合之寄宫上神为初传，中末皆取干上神",
+			[3]string{first, ganShang, ganShang}
+	}
+	return "别责", "四课不备无克无遥，阴日取支前三合为初传，中末皆取干上神",
+		[3]string{diZhi[(indexOf(diZhi, dayZhi)+4)%12], ganShang, ganShang}
+}
+
+// choose 在多个克中取一：一克径取；多克取上神阴阳与日干相比者（比用）；
+// 俱比或俱不比者涉害，取下神临孟者，次临仲者，再不能定则阳日取干上、阴日取支上
+func (s *LiuRenService) choose(candidates []liuRenLesson, yangDay bool, ganShang, zhiShang string) (string, string, string) {
+	unique := []liuRenLesson{}
+	seen := map[string]bool{}
+	for _, lesson := range candidates {
+		if !seen[lesson.upper] {
+			seen[lesson.upper] = true
+			unique = append(unique, lesson)
+		}
+	}
+	if len(unique) == 1 {
+		return unique[0].upper, "贼克", "四课克处同取一神为初传"
+	}
+
+	var bi []liuRenLesson
+	for _, lesson := range unique {
+		if (indexOf(diZhi, lesson.upper)%2 == 0) == yangDay {
+			bi = append(bi, lesson)
+		}
+	}
+	if len(bi) == 1 {
+		return bi[0].upper, "比用", "多课有克，取上神与日干阴阳相比者为初传（知一）"
+	}
+	if len(bi) == 0 {
+		bi = unique
+	}
+
+	for _, group := range []struct {
+		positions map[string]bool
+		name      string
+	}{{liuRenMeng, "孟"}, {liuRenZhong, "仲"}} {
+		var matched []liuRenLesson
+		for _, lesson := range bi {
+			if group.positions[lesson.lowerZhi] {
+				matched = append(matched, lesson)
+			}
+		}
+		if len(matched) == 1 {
+			return matched[0].upper, "涉害", fmt.Sprintf("多克俱比或俱不比，涉害取下神临%s者为初传", group.name)
+		}
+		if len(matched) > 1 {
+			break
+		}
+	}
+	if yangDay {
+		return ganShang, "涉害", "涉害深浅相等，阳日取干上神为初传"
+	}
+	return zhiShang, "涉害", "涉害深浅相等，阴日取支上神为初传"
+}
+
+// lessonRelation 课中上下之克：下克上为贼，上克下为克
+func (s *LiuRenService) lessonRelation(lesson liuRenLesson) string {
+	lower := diZhiWuXing[lesson.lower]
+	if lower == "" {
+		lower = tianGanWuXing[lesson.lower]
+	}
+	upper := diZhiWuXing[lesson.upper]
+	switch {
+	case s.zhuXingService.isKeRelation(lower, upper):
+		return "下贼上"
+	case s.zhuXingService.isKeRelation(upper, lower):
+		return "上克下"
+	}
+	return ""
+}
+
+// dunGan 旬遁之干：自日柱旬首起甲，旬空之支无干
+func (s *LiuRenService) dunGan(dayGan, dayZhi, zhi string) string {
+	start := indexOf(diZhi, string([]rune(xunShou(dayGan, dayZhi))[1]))
+	if offset := (indexOf(diZhi, zhi) - start + 12) % 12; offset < 10 {
+		return tianGan[offset]
+	}
+	return ""
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"time"
+)
+
+// LiuYaoService 六爻纳甲服务
+//
+// 以铜钱数或起卦时刻成卦，装纳甲、定八宫世应、配六亲六神，缺失的六亲取本宫首卦
+// 同位之爻为伏神；各爻并标注起卦时刻月建、日辰的生克冲合及日柱旬空。
+type LiuYaoService struct {
+	baziService     *BaziService
+	kongWangService *KongWangService
+	zhuXingService  *ZhuXingService
+}
+
+var (
+	// 六神：按日干起初爻，自下而上
+	liuShenList  = []string{"青龙", "朱雀", "勾陈", "螣蛇", "白虎", "玄武"}
+	liuShenStart = map[string]int{
+		"甲": 0, "乙": 0, "丙": 1, "丁": 1, "戊": 2, "己": 3, "庚": 4, "辛": 4, "壬": 5, "癸": 5,
+	}
+
+	// 六亲次序（伏神按此次序检查缺失）
+	liuYaoLiuQin = []string{"父母", "兄弟", "子孙", "妻财", "官鬼"}
+
+	// 铜钱数：六老阴、七少阳、八少阴、九老阳，老阴老阳为动爻
+	liuYaoCoinNames = map[int]string{6: "老阴", 7: "少阳", 8: "少阴", 9: "老阳"}
+)
+
+// 时间起卦的 method 标记：以月建、日辰之数起卦，有别于以农历月日起卦的传统起法
+const liuYaoTimeMethod = "时间（月建日辰数）"
+
+func NewLiuYaoService() *LiuYaoService {
+	return &LiuYaoService{
+		baziService:     NewBaziService(),
+		kongWangService: NewKongWangService(),
+		zhuXingService:  NewZhuXingService(),
+	}
+}
+
+// Cast 起卦排盘
+//
+// 未给铜钱数时以时间起卦：起卦时刻年支序数、月建数（寅月为一）与日辰地支序数之和
+// 除八取余为上卦，再加时支序数除八取余为下卦，除六取余为动爻（余零作八、作六）。
+// 传统时间起卦取农历月数、日数（见梅花易数），此处无农历换算，以节气月建、日辰代之，
+// 所得卦与传统起法不同，故 method 标为"时间（月建日辰数）"以示区别。
+func (s *LiuYaoService) Cast(req models.LiuYaoRequest) (*models.LiuYaoResult, error) {
+	now := time.Now()
+	date, clock := req.Date, req.Time
+	if date == "" {
+		date = now.Format("2006-01-02")
+	}
+	if clock == "" {
+		clock = now.Format("15:04")
+	}
+
+	result := &models.LiuYaoResult{
+		Question: req.Question,
+		Date:     date,
+		Time:     clock,
+		Lines:    []models.LiuYaoLine{},
+	}
+
+	bazi, err := s.baziService.calculateBaziColumns(date, clock)
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+	month, day := bazi[1], bazi[2]
+	result.Month = month.Gan + month.Zhi
+	result.Day = day.Gan + day.Zhi
+	result.XunKong = s.kongWangService.GetKongWangZhi(result.Day)
+
+	coins := req.Coins
+	result.Method = "铜钱"
+	if len(coins) == 0 {
+		coins = s.timeCoins(bazi)
+		result.Method = liuYaoTimeMethod
+	}
+	if len(coins) != 6 {
+		err = fmt.Errorf("铜钱数应为六爻")
+		result.Error = err.Error()
+		return result, err
+	}
+
+	var lines, moving [6]bool
+	for i, coin := range coins {
+		if _, exists := liuYaoCoinNames[coin]; !exists {
+			err = fmt.Errorf("第%d爻铜钱数%d无效，应为6至9", i+1, coin)
+			result.Error = err.Error()
+			return result, err
+		}
+		lines[i] = coin == 7 || coin == 9
+		moving[i] = coin == 6 || coin == 9
+	}
+
+	palace, generation := guaPalace(lines)
+	result.Original = s.hexagram(lines)
+	shi := guaShiPositions[generation]
+	ying := (shi+2)%6 + 1
+
+	naJia := s.naJia(lines)
+	liuShen := liuShenStart[day.Gan]
+	present := map[string]bool{}
+	for i := range lines {
+		zhi := naJia[i][1]
+		line := models.LiuYaoLine{
+			Position: i + 1,
+			Yang:     lines[i],
+			Moving:   moving[i],
+			Coin:     liuYaoCoinNames[coins[i]],
+			GanZhi:   naJia[i][0] + zhi,
+			WuXing:   diZhiWuXing[zhi],
+			LiuQin:   liuQinOf(palace.wuXing, diZhiWuXing[zhi]),
+			LiuShen:  liuShenList[(liuShen+i)%6],
+			Notes:    s.notes(zhi, month.Zhi, day.Zhi, result.XunKong),
+		}
+		switch i + 1 {
+		case shi:
+			line.ShiYing = "世"
+		case ying:
+			line.ShiYing = "应"
+		}
+		present[line.LiuQin] = true
+		result.Lines = append(result.Lines, line)
+	}
+
+	// 伏神：卦中不见的六亲，取本宫首卦同位之爻
+	pure := s.naJia(palaceGua(palace, 0))
+	for _, liuQin := range liuYaoLiuQin {
+		if present[liuQin] {
+			continue
+		}
+		for i, ganZhi := range pure {
+			wuXing := diZhiWuXing[ganZhi[1]]
+			if liuQinOf(palace.wuXing, wuXing) == liuQin {
+				result.Lines[i].FuShen = liuQin + ganZhi[0] + ganZhi[1] + wuXing
+				break
+			}
+		}
+	}
+
+	// 变卦：动爻所化之爻仍以本卦之宫论六亲
+	changed := changeGua(lines, moving)
+	if changed != lines {
+		hexagram := s.hexagram(changed)
+		result.Changed = &hexagram
+		changedNaJia := s.naJia(changed)
+		for i := range result.Lines {
+			if !moving[i] {
+				continue
+			}
+			wuXing := diZhiWuXing[changedNaJia[i][1]]
+			result.Lines[i].Change = &models.LiuYaoChange{
+				Yang:     changed[i],
+				GanZhi:   changedNaJia[i][0] + changedNaJia[i][1],
+				WuXing:   wuXing,
+				LiuQin:   liuQinOf(palace.wuXing, wuXing),
+				Relation: s.changeRelation(result.Lines[i].WuXing, wuXing),
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// timeCoins 时间起卦（以月建、日辰代农历月日），换算为六爻铜钱数
+func (s *LiuYaoService) timeCoins(bazi []models.BaziColumn) []int {
+	sum := indexOf(diZhi, bazi[0].Zhi) + 1 + (indexOf(diZhi, bazi[1].Zhi)+10)%12 + 1 + indexOf(diZhi, bazi[2].Zhi) + 1
+	total := sum + indexOf(diZhi, bazi[3].Zhi) + 1
+	lines := composeGua(baGuaByNumber(sum), baGuaByNumber(total))
+	movingLine := (total-1)%6 + 1
+
+	coins := make([]int, 6)
+	for i, yang := range lines {
+		switch {
+		case yang && i+1 == movingLine:
+			coins[i] = 9
+		case yang:
+			coins[i] = 7
+		case i+1 == movingLine:
+			coins[i] = 6
+		default:
+			coins[i] = 8
+		}
+	}
+	return coins
+}
+
+// hexagram 卦名、上下卦及所属八宫
+func (s *LiuYaoService) hexagram(lines [6]bool) models.LiuYaoHexagram {
+	upper, lower := splitGua(lines)
+	palace, generation := guaPalace(lines)
+	return models.LiuYaoHexagram{
+		Name:         guaPalaceNames[palace.name][generation],
+		Upper:        upper.name,
+		Lower:        lower.name,
+		Palace:       palace.name,
+		PalaceWuXing: palace.wuXing,
+		Generation:   guaGenerations[generation],
+	}
+}
+
+// naJia 六爻纳甲的天干、地支：内卦取下卦之内卦纳甲，外卦取上卦之外卦纳甲
+func (s *LiuYaoService) naJia(lines [6]bool) [6][2]string {
+	upper, lower := splitGua(lines)
+	var result [6][2]string
+	for i := 0; i < 3; i++ {
+		result[i] = [2]string{lower.gan[0], lower.zhi[0][i]}
+		result[i+3] = [2]string{upper.gan[1], upper.zhi[1][i]}
+	}
+	return result
+}
+
+// notes 月建、日辰对爻的作用及旬空
+func (s *LiuYaoService) notes(zhi, monthZhi, dayZhi string, xunKong []string) []string {
+	notes := []string{}
+	for _, ref := range []struct{ zhi, prefix, same, chong string }{
+		{monthZhi, "月", "临月建", "月破"},
+		{dayZhi, "日", "临日辰", "日冲"},
+	} {
+		switch {
+		case zhi == ref.zhi:
+			notes = append(notes, ref.same)
+		case isDiZhiChong(zhi, ref.zhi):
+			notes = append(notes, ref.chong)
+		case isDiZhiLiuHe(zhi, ref.zhi):
+			notes = append(notes, ref.prefix+"合")
+		case s.zhuXingService.isShengRelation(diZhiWuXing[ref.zhi], diZhiWuXing[zhi]):
+			notes = append(notes, ref.prefix+"生")
+		case s.zhuXingService.isKeRelation(diZhiWuXing[ref.zhi], diZhiWuXing[zhi]):
+			notes = append(notes, ref.prefix+"克")
+		}
+	}
+	if indexOf(xunKong, zhi) != -1 {
+		notes = append(notes, "旬空")
+	}
+	return notes
+}
+
+// changeRelation 变爻对本爻的回头生克
+func (s *LiuYaoService) changeRelation(original, changed string) string {
+	switch {
+	case original == changed:
+		return "比和"
+	case s.zhuXingService.isShengRelation(changed, original):
+		return "回头生"
+	case s.zhuXingService.isKeRelation(changed, original):
+		return "回头克"
+	case s.zhuXingService.isShengRelation(original, changed):
+		return "化泄"
+	default:
+		return "化耗"
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"time"
+)
+
+// MeiHuaService 梅花易数服务
+//
+// 以农历年月日时或两数起卦，得本卦、互卦、变卦；动爻所在之卦为用，另一卦为体，
+// 以体用五行生克断吉凶，并参看互卦、变卦对体卦的生克。
+type MeiHuaService struct {
+	baziService    *BaziService
+	zhuXingService *ZhuXingService
+}
+
+var (
+	// 他卦对体卦的生克断语：生体大吉，比和、体克吉，体生（泄体）小凶，克体凶
+	meiHuaVerdicts = map[string]string{
+		"生体": "大吉",
+		"比和": "吉",
+		"体克": "吉",
+		"体生": "小凶",
+		"克体": "凶",
+	}
+
+	// 用卦与体卦的生克称谓
+	meiHuaYongRelations = map[string]string{
+		"比和": "比和", "生体": "用生体", "克体": "用克体", "体生": "体生用", "体克": "体克用",
+	}
+)
+
+func NewMeiHuaService() *MeiHuaService {
+	return &MeiHuaService{
+		baziService:    NewBaziService(),
+		zhuXingService: NewZhuXingService(),
+	}
+}
+
+// Cast 梅花易数起卦
+//
+// 时间起卦：年支序数、农历月数、日数之和除八取余为上卦，再加时支序数除八取余为下卦，除六取余为动爻；
+// 数字起卦：前数为上卦，后数为下卦，两数之和（给出时间时再加时支序数）除六取余为动爻。余零作八、作六。
+func (s *MeiHuaService) Cast(req models.MeiHuaRequest) (*models.MeiHuaResult, error) {
+	result := &models.MeiHuaResult{Numbers: req.Numbers}
+
+	hour := 0
+	if req.Time != "" {
+		clock, err := time.Parse("15:04", req.Time)
+		if err != nil {
+			result.Error = fmt.Sprintf("时间格式错误: %v", err)
+			return result, err
+		}
+		hour = ((clock.Hour()+1)/2)%12 + 1
+	}
+
+	var upperNumber, lowerNumber, total int
+	switch {
+	case len(req.Numbers) == 2:
+		result.Method = "数字"
+		// 先各自取余再相加，避免大数相加溢出
+		upperNumber, lowerNumber = req.Numbers[0]%8, req.Numbers[1]%8
+		total = req.Numbers[0]%6 + req.Numbers[1]%6 + hour
+	case len(req.Numbers) != 0:
+		err := fmt.Errorf("数字起卦需提供两个数")
+		result.Error = err.Error()
+		return result, err
+	case req.LunarYear == 0 || req.LunarMonth == 0 || req.LunarDay == 0 || hour == 0:
+		err := fmt.Errorf("时间起卦需提供农历年、月、日及时间")
+		result.Error = err.Error()
+		return result, err
+	default:
+		result.Method = "时间"
+		year := s.baziService.calculateYearColumn(req.LunarYear)
+		result.LunarDate = fmt.Sprintf("%s%s年%s%s%s时", year.Gan, year.Zhi,
+			lunarMonthNames[req.LunarMonth-1], lunarDayNames[req.LunarDay-1], diZhi[hour-1])
+		upperNumber = indexOf(diZhi, year.Zhi) + 1 + req.LunarMonth + req.LunarDay
+		lowerNumber = upperNumber + hour
+		total = lowerNumber
+	}
+
+	upper, lower := baGuaByNumber(upperNumber), baGuaByNumber(lowerNumber)
+	movingLine := ((total-1)%6+6)%6 + 1
+	lines := composeGua(upper, lower)
+	var moving [6]bool
+	moving[movingLine-1] = true
+
+	mutual := [6]bool{lines[1], lines[2], lines[3], lines[2], lines[3], lines[4]}
+	changed := changeGua(lines, moving)
+
+	result.MovingLine = movingLine
+	result.Original = s.hexagram(lines)
+	result.Mutual = s.hexagram(mutual)
+	result.Changed = s.hexagram(changed)
+
+	// 动爻在下卦则下卦为用、上卦为体，反之亦然
+	ti, yong := upper, lower
+	changedUpper, changedLower := splitGua(changed)
+	changedYong := changedLower
+	if movingLine > 3 {
+		ti, yong = lower, upper
+		changedYong = changedUpper
+	}
+	result.Ti = ti.name
+	result.TiWuXing = ti.wuXing
+	result.Yong = yong.name
+	result.YongWuXing = yong.wuXing
+	relation := s.relate(ti.wuXing, yong.wuXing)
+	result.Relation = meiHuaYongRelations[relation]
+	result.Verdict = meiHuaVerdicts[relation]
+
+	mutualUpper, mutualLower := splitGua(mutual)
+	result.Relations = []models.MeiHuaRelation{}
+	for _, item := range []struct {
+		name string
+		gua  baGua
+	}{
+		{"用卦", yong},
+		{"互卦上卦", mutualUpper},
+		{"互卦下卦", mutualLower},
+		{"变卦", changedYong},
+	} {
+		relation := s.relate(ti.wuXing, item.gua.wuXing)
+		result.Relations = append(result.Relations, models.MeiHuaRelation{
+			Gua:      item.name,
+			Trigram:  item.gua.name,
+			WuXing:   item.gua.wuXing,
+			Relation: relation,
+			Verdict:  meiHuaVerdicts[relation],
+		})
+	}
+
+	return result, nil
+}
+
+// hexagram 卦名及上下卦
+func (s *MeiHuaService) hexagram(lines [6]bool) models.MeiHuaHexagram {
+	upper, lower := splitGua(lines)
+	return models.MeiHuaHexagram{
+		Name:        guaName(lines),
+		Upper:       upper.name,
+		Lower:       lower.name,
+		UpperWuXing: upper.wuXing,
+		LowerWuXing: lower.wuXing,
+		Lines:       lines[:],
+	}
+}
+
+// relate 他卦与体卦的生克：比和、生体、体生、克体、体克
+func (s *MeiHuaService) relate(ti, other string) string {
+	switch {
+	case ti == other:
+		return "比和"
+	case s.zhuXingService.isShengRelation(other, ti):
+		return "生体"
+	case s.zhuXingService.isShengRelation(ti, other):
+		return "体生"
+	case s.zhuXingService.isKeRelation(other, ti):
+		return "克体"
+	default:
+		return "体克"
+	}
+}
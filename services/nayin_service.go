@@ -1,7 +1,22 @@
 package services
 
+import (
+	"auspire/models"
+	"fmt"
+	"strings"
+)
+
 // NaYinService 纳音服务
-type NaYinService struct{}
+type NaYinService struct {
+	zhuXingService *ZhuXingService
+}
+
+// naYinException 纳音受克的特例：某些纳音逢克不忌，甚至喜克
+type naYinException struct {
+	KeWuXing string // 来克的五行
+	Effect   string // 不怕/喜
+	Note     string
+}
 
 var (
 	// 纳音五行对照表（以年柱干支组合查询）
@@ -22,10 +37,28 @@ var (
 		"丙辰": "沙中土", "丁巳": "沙中土", "戊午": "天上火", "己未": "天上火",
 		"庚申": "石榴木", "辛酉": "石榴木", "壬戌": "大海水", "癸亥": "大海水",
 	}
+
+	// 纳音受克特例（据《三命通会·论纳音取象》）
+	naYinExceptions = map[string]naYinException{
+		"海中金": {KeWuXing: "火", Effect: "不怕", Note: "海中金藏于水底，火不能伤"},
+		"砂中金": {KeWuXing: "火", Effect: "喜", Note: "砂中金非火不能炼成器"},
+		"剑锋金": {KeWuXing: "火", Effect: "喜", Note: "剑锋金得火锻炼方成利器"},
+		"天上火": {KeWuXing: "水", Effect: "喜", Note: "天上火为太阳之火，得水相映而辉"},
+		"霹雳火": {KeWuXing: "水", Effect: "喜", Note: "霹雳火得水而雷雨相济"},
+		"山下火": {KeWuXing: "水", Effect: "喜", Note: "山下火得水成既济之象"},
+		"平地木": {KeWuXing: "金", Effect: "喜", Note: "平地木得金斫削方成材"},
+		"天河水": {KeWuXing: "土", Effect: "不怕", Note: "天河水自天而降，土不能克"},
+		"大海水": {KeWuXing: "土", Effect: "不怕", Note: "大海水汪洋无际，土不能克"},
+		"路旁土": {KeWuXing: "木", Effect: "喜", Note: "路旁土得木成荫，反为有用"},
+		"大驿土": {KeWuXing: "木", Effect: "喜", Note: "大驿土得木疏通，反为有用"},
+		"沙中土": {KeWuXing: "木", Effect: "喜", Note: "沙中土得木培护，反为有用"},
+	}
 )
 
 func NewNaYinService() *NaYinService {
-	return &NaYinService{}
+	return &NaYinService{
+		zhuXingService: NewZhuXingService(),
+	}
 }
 
 // Calculate 计算纳音
@@ -40,4 +73,102 @@ func (s *NaYinService) Calculate(gan, zhi string) string {
 // GetAllNaYin 获取所有纳音数据
 func (s *NaYinService) GetAllNaYin() map[string]string {
 	return naYinData
-}
\ No newline at end of file
+}
+
+// naYinWuXing 纳音五行（取纳音名末字）
+func naYinWuXing(naYin string) string {
+	runes := []rune(naYin)
+	if len(runes) == 0 {
+		return ""
+	}
+	return string(runes[len(runes)-1])
+}
+
+// Relate 以日柱纳音为我，判断对方纳音与我的生克及吉凶
+func (s *NaYinService) Relate(dayNaYin, otherNaYin string) (relation, verdict, description string) {
+	me, other := naYinWuXing(dayNaYin), naYinWuXing(otherNaYin)
+
+	switch {
+	case me == "" || other == "":
+		return "", "", ""
+	case me == other:
+		return "比和", "吉", fmt.Sprintf("%s与%s同属%s，比和相助", otherNaYin, dayNaYin, me)
+	case s.zhuXingService.isShengRelation(other, me):
+		return "生我", "吉", fmt.Sprintf("%s(%s)生%s(%s)，得生扶", otherNaYin, other, dayNaYin, me)
+	case s.zhuXingService.isShengRelation(me, other):
+		return "我生", "平", fmt.Sprintf("%s(%s)生%s(%s)，主泄气", dayNaYin, me, otherNaYin, other)
+	case s.zhuXingService.isKeRelation(other, me):
+		if exception, exists := naYinExceptions[dayNaYin]; exists && exception.KeWuXing == other {
+			return "克我", s.exceptionVerdict(exception), fmt.Sprintf("%s(%s)克%s(%s)，然%s", otherNaYin, other, dayNaYin, me, exception.Note)
+		}
+		return "克我", "凶", fmt.Sprintf("%s(%s)克%s(%s)，受制", otherNaYin, other, dayNaYin, me)
+	default:
+		if exception, exists := naYinExceptions[otherNaYin]; exists && exception.KeWuXing == me {
+			return "我克", s.exceptionVerdict(exception), fmt.Sprintf("%s(%s)克%s(%s)，然%s", dayNaYin, me, otherNaYin, other, exception.Note)
+		}
+		return "我克", "平", fmt.Sprintf("%s(%s)克%s(%s)，我能制彼", dayNaYin, me, otherNaYin, other)
+	}
+}
+
+// exceptionVerdict 受克特例的吉凶：喜克者为吉，不怕克者为平
+func (s *NaYinService) exceptionVerdict(exception naYinException) string {
+	if exception.Effect == "喜" {
+		return "吉"
+	}
+	return "平"
+}
+
+// Analyze 分析四柱纳音：年、月、时柱纳音对日柱纳音的生克，年日关系为纳音论命之要
+func (s *NaYinService) Analyze(bazi []models.BaziColumn) *models.NaYinAnalysis {
+	columnNames := []string{"年柱", "月柱", "日柱", "时柱"}
+	analysis := &models.NaYinAnalysis{
+		Pillars:   make(map[string]string),
+		Relations: []models.NaYinRelation{},
+	}
+
+	for i, column := range bazi {
+		analysis.Pillars[columnNames[i]] = s.Calculate(column.Gan, column.Zhi)
+	}
+
+	dayNaYin := analysis.Pillars["日柱"]
+	for _, i := range []int{0, 1, 3} {
+		naYin := analysis.Pillars[columnNames[i]]
+		relation, verdict, description := s.Relate(dayNaYin, naYin)
+		if relation == "" {
+			continue
+		}
+		analysis.Relations = append(analysis.Relations, models.NaYinRelation{
+			Pillar:      columnNames[i],
+			NaYin:       naYin,
+			DayNaYin:    dayNaYin,
+			Relation:    relation,
+			Verdict:     verdict,
+			Description: description,
+		})
+	}
+
+	analysis.Summary = s.summarize(analysis)
+	return analysis
+}
+
+// summarize 生成纳音结论，以年日关系为主
+func (s *NaYinService) summarize(analysis *models.NaYinAnalysis) string {
+	if len(analysis.Relations) == 0 {
+		return "干支无效，无法分析纳音"
+	}
+
+	var parts []string
+	for _, relation := range analysis.Relations {
+		parts = append(parts, fmt.Sprintf("%s%s%s（%s）", relation.Pillar, relation.NaYin, relation.Relation, relation.Verdict))
+	}
+
+	yearRelation := analysis.Relations[0]
+	conclusion := "年日纳音相安"
+	switch yearRelation.Verdict {
+	case "吉":
+		conclusion = "年日纳音相得，根基有助"
+	case "凶":
+		conclusion = "年日纳音相战，早年多劳"
+	}
+	return strings.Join(parts, "；") + "。" + conclusion + "。"
+}
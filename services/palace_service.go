@@ -0,0 +1,186 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"strings"
+)
+
+// PalaceService 宫位分析服务
+//
+// 四柱各为一宫：年柱主祖上、父母，月柱主兄弟、事业，日支主本人与配偶，
+// 时柱主子女与晚年。逐宫考察所临十神、日主十二长生、神煞、空亡及与他柱的冲合，
+// 给出规则化的断语。
+type PalaceService struct{}
+
+var (
+	// 四柱宫位：年柱祖上父母、月柱兄弟事业、日支夫妻、时柱子女晚年
+	palaceNames = []string{"祖上父母宫", "兄弟事业宫", "夫妻宫", "子女晚年宫"}
+
+	// 各宫所主
+	palaceDomains = []string{"祖上、父母、早年", "兄弟、事业、青年", "本人、配偶、中年", "子女、晚年"}
+
+	// 十神归类，用于宫位断语
+	shiShenCategory = map[string]string{
+		"正财": "财星", "偏财": "财星", "正官": "正官", "七杀": "七杀",
+		"正印": "印星", "偏印": "印星", "食神": "食神", "伤官": "伤官",
+		"比肩": "比劫", "劫财": "比劫",
+	}
+
+	// 十神临宫断语（按柱序）
+	palaceStarFindings = []map[string]string{
+		{
+			"财星": "财星在祖上宫，祖上有产业，早年家境尚可",
+			"正官": "官星在祖上宫，出身门第较好，早年受管教",
+			"七杀": "七杀在祖上宫，早年家境多艰辛，受长辈严管",
+			"印星": "印星在祖上宫，出身书香或得长辈庇荫",
+			"食神": "食神在祖上宫，祖上宽厚，早年衣食无忧",
+			"伤官": "伤官在祖上宫，与长辈意见多不合，早年叛逆",
+			"比劫": "比劫在祖上宫，祖业难守，早年多自立",
+		},
+		{
+			"财星": "财星在事业宫，善于经营，宜从商理财",
+			"正官": "官星在事业宫，事业心强，宜公职或管理",
+			"七杀": "七杀在事业宫，敢闯敢拼，宜武职或竞争性行业",
+			"印星": "印星在事业宫，利学业文职，得上司提携",
+			"食神": "食神在事业宫，以才艺技术立业",
+			"伤官": "伤官在事业宫，才华外露，宜自主创业，忌与上司冲突",
+			"比劫": "比劫在事业宫，兄弟朋友多助，也易因合伙破财",
+		},
+		{
+			"财星": "财星坐夫妻宫，配偶持家有道或得配偶之财",
+			"正官": "正官坐夫妻宫，配偶端正有责任心",
+			"七杀": "七杀坐夫妻宫，配偶性刚，婚姻多磨合",
+			"印星": "印星坐夫妻宫，配偶温厚，得配偶照顾",
+			"食神": "食神坐夫妻宫，夫妻相处和乐",
+			"伤官": "伤官坐夫妻宫，言语易伤配偶，女命尤忌",
+			"比劫": "比劫坐夫妻宫，婚姻易有竞争，宜防破财及第三者",
+		},
+		{
+			"财星": "财星在子女宫，晚年财运佳，子女能聚财",
+			"正官": "官星在子女宫，子女端正有出息",
+			"七杀": "七杀在子女宫，子女性格刚强，男命多子",
+			"印星": "印星在子女宫，晚年安逸，得子女孝养",
+			"食神": "食神在子女宫，子女聪慧，晚年有口福",
+			"伤官": "伤官在子女宫，子女聪明但个性强，女命子女缘深",
+			"比劫": "比劫在子女宫，晚年开支多，宜早作规划",
+		},
+	}
+
+	// 宫位逢冲断语（按柱序）
+	palaceClashFindings = []string{
+		"祖上宫逢冲，早年离乡或与祖业缘薄",
+		"兄弟事业宫逢冲，兄弟不和或事业多变动",
+		"夫妻宫逢冲，婚姻多波折，宜晚婚",
+		"子女宫逢冲，子女缘薄或晚年奔波",
+	}
+
+	// 宫位空亡断语（按柱序）
+	palaceKongWangFindings = []string{
+		"祖上宫空亡，与祖辈缘薄，早年难得庇荫",
+		"兄弟事业宫空亡，兄弟助力少，事业宜专精",
+		"夫妻宫空亡，婚姻缘分较薄，宜多经营",
+		"子女宫空亡，子女缘分较迟，晚年宜自立",
+	}
+)
+
+func NewPalaceService() *PalaceService {
+	return &PalaceService{}
+}
+
+// Analyze 逐宫分析四柱
+//
+// 所临十神取本柱天干（日柱除外）及地支本气；十二长生取日主在本宫地支的星运。
+func (s *PalaceService) Analyze(bazi []models.BaziColumn) []models.PalaceAnalysis {
+	columnNames := []string{"年柱", "月柱", "日柱", "时柱"}
+	result := []models.PalaceAnalysis{}
+
+	for i, column := range bazi {
+		palace := models.PalaceAnalysis{
+			Pillar:     columnNames[i],
+			Palace:     palaceNames[i],
+			Domain:     palaceDomains[i],
+			ShiShen:    s.palaceStars(bazi, i),
+			ChangSheng: column.XingYun,
+			ShenSha:    []string{},
+			KongWang:   column.KongWang,
+			Relations:  []string{},
+			Findings:   []string{},
+		}
+
+		// 十神临宫
+		seen := map[string]bool{}
+		for _, shiShen := range palace.ShiShen {
+			category := shiShenCategory[shiShen]
+			if finding, exists := palaceStarFindings[i][category]; exists && !seen[category] {
+				seen[category] = true
+				palace.Findings = append(palace.Findings, finding)
+			}
+		}
+
+		// 日主十二长生
+		switch column.XingYun {
+		case "长生", "临官", "帝旺":
+			palace.Findings = append(palace.Findings, fmt.Sprintf("日主于%s坐%s，宫位气旺，%s之事多得力", palaceNames[i], column.XingYun, palaceDomains[i]))
+		case "死", "墓", "绝":
+			palace.Findings = append(palace.Findings, fmt.Sprintf("日主于%s坐%s，宫位气弱，%s之事宜多用心", palaceNames[i], column.XingYun, palaceDomains[i]))
+		}
+
+		// 神煞
+		var ji, xiong []string
+		for _, item := range column.ShenSha {
+			palace.ShenSha = append(palace.ShenSha, item.Name)
+			switch item.Category {
+			case "吉":
+				ji = append(ji, item.Name)
+			case "凶":
+				xiong = append(xiong, item.Name)
+			}
+		}
+		if len(ji) > 0 {
+			palace.Findings = append(palace.Findings, fmt.Sprintf("%s有%s扶持", palaceNames[i], strings.Join(ji, "、")))
+		}
+		if len(xiong) > 0 {
+			palace.Findings = append(palace.Findings, fmt.Sprintf("%s见%s，宜防%s方面的波折", palaceNames[i], strings.Join(xiong, "、"), palaceDomains[i]))
+		}
+
+		// 空亡
+		if column.KongWang {
+			palace.Findings = append(palace.Findings, palaceKongWangFindings[i])
+		}
+
+		// 与他柱的冲合
+		clashed := false
+		for j, other := range bazi {
+			if j == i {
+				continue
+			}
+			switch {
+			case isDiZhiChong(column.Zhi, other.Zhi):
+				palace.Relations = append(palace.Relations, fmt.Sprintf("%s%s冲%s%s", columnNames[i], column.Zhi, columnNames[j], other.Zhi))
+				clashed = true
+			case isDiZhiLiuHe(column.Zhi, other.Zhi):
+				palace.Relations = append(palace.Relations, fmt.Sprintf("%s%s合%s%s", columnNames[i], column.Zhi, columnNames[j], other.Zhi))
+			}
+		}
+		if clashed {
+			palace.Findings = append(palace.Findings, palaceClashFindings[i])
+		}
+
+		result = append(result, palace)
+	}
+
+	return result
+}
+
+// palaceStars 本宫所临十神：天干（日干为本人，不计）及地支本气
+func (s *PalaceService) palaceStars(bazi []models.BaziColumn, index int) []string {
+	stars := []string{}
+	if index != 2 && bazi[index].ZhuXing != "" {
+		stars = append(stars, bazi[index].ZhuXing)
+	}
+	if len(bazi[index].CangGan) > 0 && bazi[index].CangGan[0].ShiShen != "" {
+		stars = append(stars, bazi[index].CangGan[0].ShiShen)
+	}
+	return stars
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"time"
+)
+
+// QiMenService 时家奇门排盘服务
+//
+// 以拆补法定局：取起局时刻所在节气，按日柱符头（甲、己日）地支分上中下三元，
+// 查节气局数；阳遁顺布、阴遁逆布三奇六仪为地盘，时辰旬首所遁之仪所在宫的星、门
+// 为值符、值使，值符随时干、值使随时支转动排出天盘、人盘，值符宫起八神。
+type QiMenService struct {
+	baziService     *BaziService
+	kongWangService *KongWangService
+}
+
+var (
+	// 九宫：宫名与方位（按洛书宫数 1-9）
+	qiMenPalaceNames = []string{"", "坎", "坤", "震", "巽", "中", "乾", "兑", "艮", "离"}
+	qiMenDirections  = []string{"", "北", "西南", "东", "东南", "中", "西北", "西", "东北", "南"}
+
+	// 九宫格布局：上南下北，左东右西
+	qiMenGrid = [][]int{{4, 9, 2}, {3, 5, 7}, {8, 1, 6}}
+
+	// 外八宫顺时针次序：坎、艮、震、巽、离、坤、兑、乾
+	qiMenRing = []int{1, 8, 3, 4, 9, 2, 7, 6}
+
+	// 九星、八门的本宫（中五天禽寄坤二，中五无门）
+	qiMenStars = []string{"", "天蓬", "天芮", "天冲", "天辅", "天禽", "天心", "天柱", "天任", "天英"}
+	qiMenDoors = []string{"", "休门", "死门", "伤门", "杜门", "", "开门", "惊门", "生门", "景门"}
+
+	// 八神（阳遁顺时针、阴遁逆时针排布）
+	qiMenDeities = []string{"值符", "螣蛇", "太阴", "六合", "白虎", "玄武", "九地", "九天"}
+
+	// 三奇六仪布局次序
+	qiMenYi = []string{"戊", "己", "庚", "辛", "壬", "癸", "丁", "丙", "乙"}
+
+	// 六甲旬首所遁之仪
+	qiMenXunYi = map[string]string{
+		"甲子": "戊", "甲戌": "己", "甲申": "庚", "甲午": "辛", "甲辰": "壬", "甲寅": "癸",
+	}
+
+	// 节气局数：上、中、下元
+	qiMenJuTable = map[string][3]int{
+		"冬至": {1, 7, 4}, "小寒": {2, 8, 5}, "大寒": {3, 9, 6}, "立春": {8, 5, 2},
+		"雨水": {9, 6, 3}, "惊蛰": {1, 7, 4}, "春分": {3, 9, 6}, "清明": {4, 1, 7},
+		"谷雨": {5, 2, 8}, "立夏": {4, 1, 7}, "小满": {5, 2, 8}, "芒种": {6, 3, 9},
+		"夏至": {9, 3, 6}, "小暑": {8, 2, 5}, "大暑": {7, 1, 4}, "立秋": {2, 5, 8},
+		"处暑": {1, 4, 7}, "白露": {9, 3, 6}, "秋分": {7, 1, 4}, "寒露": {6, 9, 3},
+		"霜降": {5, 8, 2}, "立冬": {6, 9, 3}, "小雪": {5, 8, 2}, "大雪": {4, 7, 1},
+	}
+
+	// 阳遁节气：冬至至芒种，其余为阴遁
+	qiMenYangTerms = map[string]bool{
+		"冬至": true, "小寒": true, "大寒": true, "立春": true, "雨水": true, "惊蛰": true,
+		"春分": true, "清明": true, "谷雨": true, "立夏": true, "小满": true, "芒种": true,
+	}
+
+	// 三元：符头地支为子午卯酉上元，寅申巳亥中元，辰戌丑未下元
+	qiMenYuanNames = []string{"上元", "中元", "下元"}
+)
+
+func NewQiMenService() *QiMenService {
+	return &QiMenService{
+		baziService:     NewBaziService(),
+		kongWangService: NewKongWangService(),
+	}
+}
+
+// Calculate 时家奇门排盘（拆补法）
+func (s *QiMenService) Calculate(req models.QiMenRequest) (*models.QiMenResult, error) {
+	bazi, err := s.baziService.calculateBaziColumns(req.Date, req.Time)
+	if err != nil {
+		return &models.QiMenResult{Date: req.Date, Time: req.Time, Error: err.Error()}, err
+	}
+	date, _ := time.Parse("2006-01-02", req.Date)
+	day, hour := bazi[2], bazi[3]
+
+	// 定局：节气定阴阳遁，符头定三元
+	term, _ := currentTerm(date)
+	yang := qiMenYangTerms[term]
+	dayIndex := jiaZiIndex(day.Gan, day.Zhi)
+	fuTou := dayIndex - indexOf(tianGan, day.Gan)%5
+	yuan := 2
+	switch diZhi[fuTou%12] {
+	case "子", "午", "卯", "酉":
+		yuan = 0
+	case "寅", "申", "巳", "亥":
+		yuan = 1
+	}
+	ju := qiMenJuTable[term][yuan]
+
+	// 地盘：戊起局数宫，阳顺阴逆布三奇六仪
+	earth := make([]string, 10)
+	for i, yi := range qiMenYi {
+		earth[s.step(ju, i, yang)] = yi
+	}
+
+	// 值符、值使：时辰旬首所遁之仪在地盘的宫
+	xun := xunShou(hour.Gan, hour.Zhi)
+	xunYi := qiMenXunYi[xun]
+	xunPalace := indexOf(earth, xunYi)
+	zhiFu := qiMenStars[xunPalace]
+	zhiShi := qiMenDoors[s.lodge(xunPalace)]
+
+	// 天盘：值符随时干（时干为甲则取旬首之仪）所在地盘宫
+	hourGan := hour.Gan
+	if hourGan == "甲" {
+		hourGan = xunYi
+	}
+	starTarget := s.lodge(indexOf(earth, hourGan))
+	starShift := s.ringIndex(starTarget) - s.ringIndex(s.lodge(xunPalace))
+
+	// 人盘：值使自本宫按时辰距旬首的步数，阳顺阴逆行九宫（入中寄坤）
+	doorTarget := s.lodge(s.step(xunPalace, jiaZiIndex(hour.Gan, hour.Zhi)%10, yang))
+	doorShift := s.ringIndex(doorTarget) - s.ringIndex(s.lodge(xunPalace))
+
+	palaces := make([]models.QiMenPalace, 9)
+	for n := 1; n <= 9; n++ {
+		palaces[n-1] = models.QiMenPalace{
+			Number:      n,
+			Name:        qiMenPalaceNames[n],
+			Direction:   qiMenDirections[n],
+			EarthStem:   earth[n],
+			HeavenStems: []string{},
+			Stars:       []string{},
+		}
+	}
+
+	// 外八宫的星、门整体旋转；中宫天禽随天芮同行，带中宫之干
+	for i, n := range qiMenRing {
+		p := &palaces[qiMenRing[((i+starShift)%8+8)%8]-1]
+		p.Stars = append(p.Stars, qiMenStars[n])
+		p.HeavenStems = append(p.HeavenStems, earth[n])
+		if n == 2 {
+			p.Stars = append(p.Stars, qiMenStars[5])
+			p.HeavenStems = append(p.HeavenStems, earth[5])
+		}
+
+		palaces[qiMenRing[((i+doorShift)%8+8)%8]-1].Door = qiMenDoors[n]
+	}
+
+	// 八神：值符随天盘值符，阳遁顺时针、阴遁逆时针
+	start := s.ringIndex(starTarget)
+	for i, deity := range qiMenDeities {
+		offset := i
+		if !yang {
+			offset = -i
+		}
+		palaces[qiMenRing[((start+offset)%8+8)%8]-1].Deity = deity
+	}
+
+	dun := "阴遁"
+	if yang {
+		dun = "阳遁"
+	}
+	return &models.QiMenResult{
+		Date:    req.Date,
+		Time:    req.Time,
+		Bazi:    []string{bazi[0].Gan + bazi[0].Zhi, bazi[1].Gan + bazi[1].Zhi, day.Gan + day.Zhi, hour.Gan + hour.Zhi},
+		JieQi:   term,
+		Yuan:    qiMenYuanNames[yuan],
+		Dun:     dun,
+		Ju:      ju,
+		JuName:  fmt.Sprintf("%s%d局", dun, ju),
+		XunShou: xun + xunYi,
+		ZhiFu:   zhiFu,
+		ZhiShi:  zhiShi,
+		XunKong: s.kongWangService.GetKongWangZhi(hour.Gan + hour.Zhi),
+		MaXing:  shenShaTable("驿马")[diZhiSanHe[hour.Zhi]][0],
+		Palaces: palaces,
+		Grid:    qiMenGrid,
+	}, nil
+}
+
+// step 自某宫按洛书宫数顺（阳）或逆（阴）行若干步
+func (s *QiMenService) step(from, steps int, yang bool) int {
+	if !yang {
+		steps = -steps
+	}
+	return ((from-1+steps)%9+9)%9 + 1
+}
+
+// lodge 中五寄坤二
+func (s *QiMenService) lodge(palace int) int {
+	if palace == 5 {
+		return 2
+	}
+	return palace
+}
+
+// ringIndex 宫在外八宫顺时针次序中的位置
+func (s *QiMenService) ringIndex(palace int) int {
+	for i, n := range qiMenRing {
+		if n == palace {
+			return i
+		}
+	}
+	return -1
+}
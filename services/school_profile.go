@@ -0,0 +1,161 @@
+package services
+
+import "fmt"
+
+// SchoolProfile 命理流派配置
+//
+// 不同流派在十二长生（取法及土随火或随水）、子时换日、藏干取用、神煞查法及旺衰计分上
+// 各有取舍。流派配置将这些选择打包为具名、带版本的定义，计算时按请求或用户默认选用，
+// 各服务查表时均以当前流派为准，而非直接读取包级数据表。
+type SchoolProfile struct {
+	Name            string                    `json:"name"`
+	Version         string                    `json:"version"`
+	Description     string                    `json:"description"`
+	ChangShengMode  ChangShengMode            `json:"changShengMode"` // 十二长生取法
+	TuChangSheng    string                    `json:"tuChangSheng"`   // 土的十二长生随"火"（寄生于寅）或随"水"（寄生于申）
+	ZiShiMode       string                    `json:"ziShiMode"`      // 子时换日方式
+	CangGan         map[string][]CangGanEntry `json:"cangGan"`        // 地支藏干表（含本气、中气、余气及力量占比）
+	ShenShaRules    []ShenShaRule             `json:"shenShaRules"`   // 神煞规则表
+	StrengthWeights StrengthWeights           `json:"strengthWeights"`
+}
+
+// StrengthWeights 喜用神五行计分权重
+type StrengthWeights struct {
+	TianGan   int `json:"tianGan"`   // 天干每字计分
+	DiZhi     int `json:"diZhi"`     // 地支每字计分
+	CangGan   int `json:"cangGan"`   // 地支藏干每字计分（0 表示不计藏干）
+	YueLing   int `json:"yueLing"`   // 月令五行额外计分
+	Threshold int `json:"threshold"` // 日主五行得分达到该值即论偏强
+}
+
+const (
+	// DefaultSchoolProfile 未指定流派时使用的流派，排盘结果（子时、星运、自坐、十二长生）与引入流派配置前一致
+	DefaultSchoolProfile = "legacy"
+
+	// ZiShiZiZhengHuanRi 子正换日：零点换日，23 点至 24 点仍作当日论，日柱、时柱均按当日
+	ZiShiZiZhengHuanRi = "子正换日"
+	// ZiShiZiChuHuanRi 子初换日：23 点起即作次日论，日柱、时柱均取次日
+	ZiShiZiChuHuanRi = "子初换日"
+	// ZiShiZaoWanZi 早晚子时：23 点至 24 点为夜子时，日柱不换，时干按次日子时起
+	ZiShiZaoWanZi = "早晚子时"
+)
+
+var (
+	// 已注册的流派（按展示顺序）
+	schoolProfiles = []*SchoolProfile{
+		{
+			Name:           "legacy",
+			Version:        "1.0",
+			Description:    "兼容旧版（默认）：排盘与引入流派配置前一致，十二长生五行同生同死、土随火生于寅，子正换日，藏干按通行表，五行只计干支",
+			ChangShengMode: ChangShengWuXing,
+			TuChangSheng:   "火",
+			ZiShiMode:      ZiShiZiZhengHuanRi,
+			CangGan:        cangGanData,
+			ShenShaRules:   shenShaRules,
+			StrengthWeights: StrengthWeights{
+				TianGan: 1, DiZhi: 1, CangGan: 0, YueLing: 0, Threshold: 2,
+			},
+		},
+		{
+			Name:           "traditional",
+			Version:        "1.0",
+			Description:    "传统子平：十二长生阳生阴死、土随火生于寅，子初换日，藏干按通行表，五行只计干支",
+			ChangShengMode: ChangShengYangShengYinSi,
+			TuChangSheng:   "火",
+			ZiShiMode:      ZiShiZiChuHuanRi,
+			CangGan:        cangGanData,
+			ShenShaRules:   shenShaRules,
+			StrengthWeights: StrengthWeights{
+				TianGan: 1, DiZhi: 1, CangGan: 0, YueLing: 0, Threshold: 2,
+			},
+		},
+		{
+			Name:           "modern",
+			Version:        "1.0",
+			Description:    "现代派：十二长生五行同生同死、土随水生于申，分早晚子时，藏干按本气、中气、余气排列并计分，月令加权",
+			ChangShengMode: ChangShengWuXing,
+			TuChangSheng:   "水",
+			ZiShiMode:      ZiShiZaoWanZi,
+			CangGan: map[string][]CangGanEntry{
+				"子": {{"癸", "本气", 100}},
+				"丑": {{"己", "本气", 60}, {"辛", "中气", 30}, {"癸", "余气", 10}},
+				"寅": {{"甲", "本气", 60}, {"丙", "中气", 30}, {"戊", "余气", 10}},
+				"卯": {{"乙", "本气", 100}},
+				"辰": {{"戊", "本气", 60}, {"癸", "中气", 30}, {"乙", "余气", 10}},
+				"巳": {{"丙", "本气", 60}, {"庚", "中气", 30}, {"戊", "余气", 10}},
+				"午": {{"丁", "本气", 70}, {"己", "中气", 30}},
+				"未": {{"己", "本气", 60}, {"乙", "中气", 30}, {"丁", "余气", 10}},
+				"申": {{"庚", "本气", 60}, {"壬", "中气", 30}, {"戊", "余气", 10}},
+				"酉": {{"辛", "本气", 100}},
+				"戌": {{"戊", "本气", 60}, {"丁", "中气", 30}, {"辛", "余气", 10}},
+				"亥": {{"壬", "本气", 70}, {"甲", "中气", 30}},
+			},
+			ShenShaRules: modernShenShaRules(),
+			StrengthWeights: StrengthWeights{
+				TianGan: 2, DiZhi: 2, CangGan: 1, YueLing: 2, Threshold: 6,
+			},
+		},
+	}
+)
+
+// modernShenShaRules 现代派神煞：天乙贵人取「甲戊兼牛羊，庚辛逢虎马」，文昌只以日干查
+func modernShenShaRules() []ShenShaRule {
+	rules := make([]ShenShaRule, len(shenShaRules))
+	copy(rules, shenShaRules)
+
+	for i := range rules {
+		switch rules[i].Name {
+		case "天乙贵人":
+			table := make(map[string][]string, len(rules[i].Table))
+			for key, values := range rules[i].Table {
+				table[key] = values
+			}
+			table["庚"] = []string{"寅", "午"}
+			rules[i].Table = table
+			rules[i].Sources = []string{"星平会海·天乙贵人歌"}
+		case "文昌贵人":
+			rules[i].Bases = []ShenShaBasis{BasisRiGan}
+		}
+	}
+
+	return rules
+}
+
+// GetSchoolProfile 按名称获取流派配置，名称为空时返回默认流派
+func GetSchoolProfile(name string) (*SchoolProfile, error) {
+	if name == "" {
+		name = DefaultSchoolProfile
+	}
+	for _, profile := range schoolProfiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return nil, fmt.Errorf("未知的流派: %s", name)
+}
+
+// GetAllSchoolProfiles 获取全部已注册的流派配置
+func GetAllSchoolProfiles() []*SchoolProfile {
+	return schoolProfiles
+}
+
+// defaultSchoolProfile 返回默认流派配置
+func defaultSchoolProfile() *SchoolProfile {
+	profile, _ := GetSchoolProfile(DefaultSchoolProfile)
+	return profile
+}
+
+// ID 流派标识（名称@版本），随计算结果返回
+func (p *SchoolProfile) ID() string {
+	return p.Name + "@" + p.Version
+}
+
+// changSheng 按流派的十二长生取法及土的寄生查询天干在地支的十二长生状态
+func (p *SchoolProfile) changSheng(gan, zhi string) string {
+	return ZhangShengPosition(gan, zhi, p.ChangShengMode, p.TuChangSheng)
+}
+
+// changShengRule 十二长生取法说明，用于计算过程追溯
+func (p *SchoolProfile) changShengRule() string {
+	return fmt.Sprintf("%s，土随%s", p.ChangShengMode, p.TuChangSheng)
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// 命局检索查询语言
+//
+// 示例：dayGan=甲 AND monthZhi IN (寅,卯) AND hasShenSha(天乙贵人)
+//
+//	expr    := or
+//	or      := and { OR and }
+//	and     := unary { AND unary }
+//	unary   := NOT unary | primary
+//	primary := "(" expr ")" | 函数 "(" 参数 { "," 参数 } ")"
+//	         | 字段 ("=" | "!=") 值 | 字段 [NOT] IN "(" 值 { "," 值 } ")"
+//
+// 关键字不区分大小写。字段为 year/month/day/hour 加 Gan/Zhi/Pillar，
+// 如 dayGan、monthZhi、hourPillar；函数为 hasShenSha(神煞[,柱]) 与 hasShiShen(十神[,柱])，
+// 柱取 year/month/day/hour，省略时四柱任一命中即可。
+
+// searchExpr 查询表达式节点
+type searchExpr interface {
+	match(bazi []models.BaziColumn) bool
+}
+
+type searchAnd struct{ left, right searchExpr }
+type searchOr struct{ left, right searchExpr }
+type searchNot struct{ expr searchExpr }
+
+// searchCompare 字段比较：= / != / IN / NOT IN
+type searchCompare struct {
+	pillar int
+	part   string // Gan/Zhi/Pillar
+	values []string
+	negate bool
+}
+
+// searchCall 函数调用：hasShenSha / hasShiShen
+type searchCall struct {
+	name    string
+	arg     string
+	pillars []int
+}
+
+func (e searchAnd) match(bazi []models.BaziColumn) bool {
+	return e.left.match(bazi) && e.right.match(bazi)
+}
+
+func (e searchOr) match(bazi []models.BaziColumn) bool {
+	return e.left.match(bazi) || e.right.match(bazi)
+}
+
+func (e searchNot) match(bazi []models.BaziColumn) bool {
+	return !e.expr.match(bazi)
+}
+
+func (e searchCompare) match(bazi []models.BaziColumn) bool {
+	column := bazi[e.pillar]
+	value := column.Gan + column.Zhi
+	switch e.part {
+	case "Gan":
+		value = column.Gan
+	case "Zhi":
+		value = column.Zhi
+	}
+	for _, v := range e.values {
+		if v == value {
+			return !e.negate
+		}
+	}
+	return e.negate
+}
+
+func (e searchCall) match(bazi []models.BaziColumn) bool {
+	for _, i := range e.pillars {
+		switch e.name {
+		case "hasShenSha":
+			for _, item := range bazi[i].ShenSha {
+				if item.Name == e.arg {
+					return true
+				}
+			}
+		case "hasShiShen":
+			// 日干为日主本身，不计十神
+			if i != 2 && bazi[i].ZhuXing == e.arg {
+				return true
+			}
+			for _, item := range bazi[i].CangGan {
+				if item.ShiShen == e.arg {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+var (
+	// 查询字段中的柱名
+	searchPillars = map[string]int{"year": 0, "month": 1, "day": 2, "hour": 3}
+
+	// 查询函数（均依赖十神、神煞等增强计算）
+	searchFunctions = map[string]bool{"hasShenSha": true, "hasShiShen": true}
+)
+
+// searchToken 词法单元
+type searchToken struct {
+	kind string // word/keyword/punct/eof
+	text string
+	pos  int
+}
+
+// searchParser 查询语言解析器
+type searchParser struct {
+	tokens       []searchToken
+	current      int
+	shenShaNames map[string]bool // 当前流派可用的神煞名
+	enhance      bool            // 查询是否用到十神、神煞
+}
+
+// parseSearchQuery 解析查询语句，返回表达式及是否需要增强计算
+func parseSearchQuery(query string, shenShaNames map[string]bool) (searchExpr, bool, error) {
+	tokens, err := tokenizeSearchQuery(query)
+	if err != nil {
+		return nil, false, err
+	}
+	parser := &searchParser{tokens: tokens, shenShaNames: shenShaNames}
+	expr, err := parser.parseOr()
+	if err != nil {
+		return nil, false, err
+	}
+	if token := parser.peek(); token.kind != "eof" {
+		return nil, false, fmt.Errorf("查询语法错误：位置%d处多余的\"%s\"", token.pos, token.text)
+	}
+	return expr, parser.enhance, nil
+}
+
+// tokenizeSearchQuery 词法分析：括号、逗号、= 与 != 为符号，AND/OR/NOT/IN 为关键字，其余连续字符为词
+func tokenizeSearchQuery(query string) ([]searchToken, error) {
+	tokens := []searchToken{}
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',' || r == '，' || r == '=':
+			text := string(r)
+			if r == '，' {
+				text = ","
+			}
+			tokens = append(tokens, searchToken{kind: "punct", text: text, pos: i})
+			i++
+		case r == '!':
+			if i+1 >= len(runes) || runes[i+1] != '=' {
+				return nil, fmt.Errorf("查询语法错误：位置%d处的\"!\"应为\"!=\"", i)
+			}
+			tokens = append(tokens, searchToken{kind: "punct", text: "!=", pos: i})
+			i += 2
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("(),，=!", runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			kind := "word"
+			switch strings.ToUpper(text) {
+			case "AND", "OR", "NOT", "IN":
+				kind, text = "keyword", strings.ToUpper(text)
+			}
+			tokens = append(tokens, searchToken{kind: kind, text: text, pos: start})
+		}
+	}
+
+	return append(tokens, searchToken{kind: "eof", pos: len(runes)}), nil
+}
+
+func (p *searchParser) peek() searchToken {
+	return p.tokens[p.current]
+}
+
+func (p *searchParser) next() searchToken {
+	token := p.tokens[p.current]
+	if token.kind != "eof" {
+		p.current++
+	}
+	return token
+}
+
+// accept 当前词为指定类型与文本时前进并返回 true
+func (p *searchParser) accept(kind, text string) bool {
+	if token := p.peek(); token.kind == kind && token.text == text {
+		p.current++
+		return true
+	}
+	return false
+}
+
+// expect 要求当前词为指定符号
+func (p *searchParser) expect(text string) error {
+	if !p.accept("punct", text) {
+		token := p.peek()
+		return fmt.Errorf("查询语法错误：位置%d处应为\"%s\"", token.pos, text)
+	}
+	return nil
+}
+
+func (p *searchParser) parseOr() (searchExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("keyword", "OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = searchOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *searchParser) parseAnd() (searchExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("keyword", "AND") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = searchAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *searchParser) parseUnary() (searchExpr, error) {
+	if p.accept("keyword", "NOT") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return searchNot{expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *searchParser) parsePrimary() (searchExpr, error) {
+	if p.accept("punct", "(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+
+	token := p.next()
+	if token.kind != "word" {
+		return nil, fmt.Errorf("查询语法错误：位置%d处应为字段或函数", token.pos)
+	}
+	if searchFunctions[token.text] {
+		return p.parseCall(token)
+	}
+	return p.parseCompare(token)
+}
+
+// parseCall 解析函数调用
+func (p *searchParser) parseCall(name searchToken) (searchExpr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args, err := p.parseValues()
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 2 {
+		return nil, fmt.Errorf("查询语法错误：%s最多两个参数", name.text)
+	}
+
+	call := searchCall{name: name.text, arg: args[0], pillars: []int{0, 1, 2, 3}}
+	if name.text == "hasShiShen" && indexOf(shiShenOrder, args[0]) == -1 {
+		return nil, fmt.Errorf("查询语法错误：未知十神\"%s\"", args[0])
+	}
+	if name.text == "hasShenSha" && !p.shenShaNames[args[0]] {
+		return nil, fmt.Errorf("查询语法错误：未知神煞\"%s\"", args[0])
+	}
+	if len(args) == 2 {
+		pillar, exists := searchPillars[args[1]]
+		if !exists {
+			return nil, fmt.Errorf("查询语法错误：未知柱\"%s\"，应为year/month/day/hour", args[1])
+		}
+		call.pillars = []int{pillar}
+	}
+	p.enhance = true
+	return call, nil
+}
+
+// parseCompare 解析字段比较
+func (p *searchParser) parseCompare(field searchToken) (searchExpr, error) {
+	compare, err := p.parseField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.accept("punct", "=") || p.accept("punct", "!=") {
+		compare.negate = p.tokens[p.current-1].text == "!="
+		value := p.next()
+		if value.kind != "word" {
+			return nil, fmt.Errorf("查询语法错误：位置%d处应为取值", value.pos)
+		}
+		compare.values = []string{value.text}
+	} else {
+		compare.negate = p.accept("keyword", "NOT")
+		if !p.accept("keyword", "IN") {
+			token := p.peek()
+			return nil, fmt.Errorf("查询语法错误：位置%d处应为=、!=或IN", token.pos)
+		}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		if compare.values, err = p.parseValues(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, value := range compare.values {
+		if err := compare.validate(field.text, value); err != nil {
+			return nil, err
+		}
+	}
+	return compare, nil
+}
+
+// parseField 解析字段名，如 monthZhi
+func (p *searchParser) parseField(field searchToken) (searchCompare, error) {
+	for name, pillar := range searchPillars {
+		for _, part := range []string{"Gan", "Zhi", "Pillar"} {
+			if field.text == name+part {
+				return searchCompare{pillar: pillar, part: part}, nil
+			}
+		}
+	}
+	return searchCompare{}, fmt.Errorf("查询语法错误：未知字段\"%s\"", field.text)
+}
+
+// parseValues 解析以逗号分隔、右括号结尾的取值列表
+func (p *searchParser) parseValues() ([]string, error) {
+	values := []string{}
+	for {
+		value := p.next()
+		if value.kind != "word" {
+			return nil, fmt.Errorf("查询语法错误：位置%d处应为取值", value.pos)
+		}
+		values = append(values, value.text)
+		if p.accept("punct", ")") {
+			return values, nil
+		}
+		if !p.accept("punct", ",") {
+			token := p.peek()
+			return nil, fmt.Errorf("查询语法错误：位置%d处应为\",\"或\")\"", token.pos)
+		}
+	}
+}
+
+// validate 校验取值为合法的天干、地支或干支
+func (e searchCompare) validate(field, value string) error {
+	chars := []rune(value)
+	switch {
+	case e.part == "Gan" && indexOf(tianGan, value) == -1:
+		return fmt.Errorf("查询语法错误：%s的取值\"%s\"不是天干", field, value)
+	case e.part == "Zhi" && indexOf(diZhi, value) == -1:
+		return fmt.Errorf("查询语法错误：%s的取值\"%s\"不是地支", field, value)
+	case e.part == "Pillar" && (len(chars) != 2 || jiaZiIndex(string(chars[0]), string(chars[1])) == -1):
+		return fmt.Errorf("查询语法错误：%s的取值\"%s\"不是六十甲子", field, value)
+	}
+	return nil
+}
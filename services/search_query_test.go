@@ -0,0 +1,113 @@
+package services
+
+import (
+	"auspire/models"
+	"testing"
+)
+
+// searchTestChart 固定命局：甲子年 丙寅月 戊辰日 庚申时
+func searchTestChart() []models.BaziColumn {
+	return []models.BaziColumn{
+		{Gan: "甲", Zhi: "子", ZhuXing: "七杀",
+			CangGan: []models.CangGanItem{{Gan: "癸", ShiShen: "正财"}}},
+		{Gan: "丙", Zhi: "寅", ZhuXing: "偏印",
+			CangGan: []models.CangGanItem{{Gan: "甲", ShiShen: "七杀"}, {Gan: "丙", ShiShen: "偏印"}, {Gan: "戊", ShiShen: "比肩"}},
+			ShenSha: []models.ShenShaItem{{Name: "驿马"}}},
+		{Gan: "戊", Zhi: "辰", ZhuXing: "日主",
+			CangGan: []models.CangGanItem{{Gan: "戊", ShiShen: "比肩"}, {Gan: "乙", ShiShen: "正官"}, {Gan: "癸", ShiShen: "正财"}}},
+		{Gan: "庚", Zhi: "申", ZhuXing: "食神",
+			CangGan: []models.CangGanItem{{Gan: "庚", ShiShen: "食神"}, {Gan: "壬", ShiShen: "偏财"}, {Gan: "戊", ShiShen: "比肩"}},
+			ShenSha: []models.ShenShaItem{{Name: "文昌贵人"}}},
+	}
+}
+
+func searchTestShenShaNames() map[string]bool {
+	names := map[string]bool{}
+	for _, rule := range shenShaRules {
+		names[rule.Name] = true
+	}
+	return names
+}
+
+func TestParseSearchQueryMatch(t *testing.T) {
+	tests := []struct {
+		query   string
+		match   bool
+		enhance bool
+	}{
+		{"dayGan=戊", true, false},
+		{"dayGan != 戊", false, false},
+		{"yearPillar=甲子 and hourZhi=申", true, false},
+		{"monthZhi IN (寅,卯)", true, false},
+		{"monthZhi NOT IN (寅，卯)", false, false},
+		{"hourGan not in (甲,乙)", true, false},
+		// AND 优先于 OR：dayGan=戊 OR (dayGan=甲 AND monthZhi=子)
+		{"dayGan=戊 OR dayGan=甲 AND monthZhi=子", true, false},
+		{"(dayGan=戊 OR dayGan=甲) AND monthZhi=子", false, false},
+		// NOT 只作用于紧随的一项：(NOT dayGan=甲) AND yearZhi=子
+		{"NOT dayGan=甲 AND yearZhi=子", true, false},
+		{"NOT (dayGan=戊 AND yearZhi=子)", false, false},
+		{"NOT NOT dayGan=戊", true, false},
+		{"hasShenSha(驿马)", true, true},
+		{"hasShenSha(驿马, hour)", false, true},
+		{"hasShenSha(文昌贵人,hour)", true, true},
+		{"hasShiShen(七杀)", true, true},
+		{"hasShiShen(七杀,day)", false, true},
+		{"hasShiShen(正官,day)", true, true},
+		{"hasShiShen(伤官)", false, true},
+		{"dayGan=戊 AND NOT hasShiShen(伤官)", true, true},
+	}
+
+	chart := searchTestChart()
+	for _, tt := range tests {
+		expr, enhance, err := parseSearchQuery(tt.query, searchTestShenShaNames())
+		if err != nil {
+			t.Errorf("parseSearchQuery(%q) error: %v", tt.query, err)
+			continue
+		}
+		if enhance != tt.enhance {
+			t.Errorf("parseSearchQuery(%q) enhance = %v, want %v", tt.query, enhance, tt.enhance)
+		}
+		if got := expr.match(chart); got != tt.match {
+			t.Errorf("parseSearchQuery(%q).match = %v, want %v", tt.query, got, tt.match)
+		}
+	}
+}
+
+func TestParseSearchQueryError(t *testing.T) {
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{"", "查询语法错误：位置0处应为字段或函数"},
+		{"dayGan=甲 AND", "查询语法错误：位置12处应为字段或函数"},
+		{"dayGan 甲", "查询语法错误：位置7处应为=、!=或IN"},
+		{"dayGan ! 甲", "查询语法错误：位置7处的\"!\"应为\"!=\""},
+		{"dayGan=", "查询语法错误：位置7处应为取值"},
+		{"(dayGan=甲", "查询语法错误：位置9处应为\")\""},
+		{"dayGan=甲)", "查询语法错误：位置8处多余的\")\""},
+		{"dayGan=甲 yearZhi=子", "查询语法错误：位置9处多余的\"yearZhi\""},
+		{"monthZhi IN 寅", "查询语法错误：位置12处应为\"(\""},
+		{"monthZhi IN (寅,卯", "查询语法错误：位置16处应为\",\"或\")\""},
+		{"monthZhi NOT (寅)", "查询语法错误：位置13处应为=、!=或IN"},
+		{"weekGan=甲", "查询语法错误：未知字段\"weekGan\""},
+		{"dayGan=子", "查询语法错误：dayGan的取值\"子\"不是天干"},
+		{"monthZhi IN (寅,甲)", "查询语法错误：monthZhi的取值\"甲\"不是地支"},
+		{"dayPillar=甲丑", "查询语法错误：dayPillar的取值\"甲丑\"不是六十甲子"},
+		{"hasShenSha(不存在)", "查询语法错误：未知神煞\"不存在\""},
+		{"hasShiShen(财星)", "查询语法错误：未知十神\"财星\""},
+		{"hasShiShen(七杀,week)", "查询语法错误：未知柱\"week\"，应为year/month/day/hour"},
+		{"hasShiShen(七杀,day,hour)", "查询语法错误：hasShiShen最多两个参数"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := parseSearchQuery(tt.query, searchTestShenShaNames())
+		if err == nil {
+			t.Errorf("parseSearchQuery(%q) error = nil, want %q", tt.query, tt.err)
+			continue
+		}
+		if err.Error() != tt.err {
+			t.Errorf("parseSearchQuery(%q) error = %q, want %q", tt.query, err.Error(), tt.err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SearchService 命局检索服务
+//
+// 在日期范围内逐时辰排盘，以查询语言筛选满足条件的时刻，并将连续命中的时辰
+// 合并为时间段返回，供研究统计或寻找例盘之用。排盘沿用 BaziService 的流程，
+// 查询用到十神、神煞时才执行增强计算。
+type SearchService struct {
+	profile     *SchoolProfile
+	baziService *BaziService
+}
+
+// searchStep 检索步长：一个时辰的起止钟点
+type searchStep struct {
+	start, end string
+}
+
+var (
+	// 每日检索的时辰：子时分早子（零点）与夜子（23 点），其余时辰取奇数整点起
+	searchSteps = []searchStep{
+		{"00:00", "00:59"}, {"01:00", "02:59"}, {"03:00", "04:59"}, {"05:00", "06:59"},
+		{"07:00", "08:59"}, {"09:00", "10:59"}, {"11:00", "12:59"}, {"13:00", "14:59"},
+		{"15:00", "16:59"}, {"17:00", "18:59"}, {"19:00", "20:59"}, {"21:00", "22:59"},
+		{"23:00", "23:59"},
+	}
+)
+
+const (
+	// 检索范围上限（天）
+	searchMaxDays = 366
+	// 默认及最多返回的时间段数
+	searchDefaultLimit = 50
+	searchMaxLimit     = 500
+)
+
+func NewSearchService() *SearchService {
+	return newSearchService(defaultSchoolProfile())
+}
+
+// newSearchService 按流派创建检索服务，子时换日、神煞等均采用该流派
+func newSearchService(profile *SchoolProfile) *SearchService {
+	return &SearchService{
+		profile:     profile,
+		baziService: newBaziService(profile),
+	}
+}
+
+// withProfile 返回绑定指定流派的服务实例，流派相同时返回自身
+func (s *SearchService) withProfile(profile *SchoolProfile) *SearchService {
+	if profile == s.profile {
+		return s
+	}
+	return newSearchService(profile)
+}
+
+// Search 按查询语句检索日期范围内的命局
+func (s *SearchService) Search(req models.SearchRequest, opts CalcOptions) (*models.SearchResult, error) {
+	s = s.withProfile(opts.profile())
+
+	result := &models.SearchResult{
+		Query:   req.Query,
+		Windows: []models.SearchWindow{},
+		School:  s.profile.ID(),
+	}
+
+	shenShaNames := map[string]bool{}
+	for _, rule := range s.profile.ShenShaRules {
+		shenShaNames[rule.Name] = true
+	}
+	expr, enhance, err := parseSearchQuery(req.Query, shenShaNames)
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		result.Error = fmt.Sprintf("开始日期格式错误: %v", err)
+		return result, err
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		result.Error = fmt.Sprintf("结束日期格式错误: %v", err)
+		return result, err
+	}
+	if end.Before(start) {
+		err = fmt.Errorf("结束日期不能早于开始日期")
+		result.Error = err.Error()
+		return result, err
+	}
+	if end.Sub(start).Hours()/24 >= searchMaxDays {
+		err = fmt.Errorf("检索范围不能超过%d天", searchMaxDays)
+		result.Error = err.Error()
+		return result, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = searchDefaultLimit
+	}
+	if limit > searchMaxLimit {
+		limit = searchMaxLimit
+	}
+
+	var window *models.SearchWindow
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		day := date.Format("2006-01-02")
+		for _, step := range searchSteps {
+			bazi, err := s.baziService.calculateBaziColumns(day, step.start)
+			if err != nil {
+				result.Error = err.Error()
+				return result, err
+			}
+			if enhance {
+				bazi = s.baziService.enhanceBaziColumns(bazi, nil)
+			}
+
+			if !expr.match(bazi) {
+				window = nil
+				continue
+			}
+			result.Matched++
+
+			pillars := s.pillars(bazi)
+			if window != nil {
+				window.End = day + " " + step.end
+				if window.Pillars[len(window.Pillars)-1] != pillars {
+					window.Pillars = append(window.Pillars, pillars)
+				}
+				continue
+			}
+			if len(result.Windows) == limit {
+				result.Truncated = true
+				return result, nil
+			}
+			result.Windows = append(result.Windows, models.SearchWindow{
+				Start:   day + " " + step.start,
+				End:     day + " " + step.end,
+				Pillars: []string{pillars},
+			})
+			window = &result.Windows[len(result.Windows)-1]
+		}
+	}
+
+	return result, nil
+}
+
+// pillars 四柱干支文本，如 "丙午 庚子 壬申 庚子"
+func (s *SearchService) pillars(bazi []models.BaziColumn) string {
+	parts := make([]string, 0, len(bazi))
+	for _, column := range bazi {
+		parts = append(parts, column.Gan+column.Zhi)
+	}
+	return strings.Join(parts, " ")
+}
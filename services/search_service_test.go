@@ -0,0 +1,70 @@
+package services
+
+import (
+	"auspire/models"
+	"testing"
+)
+
+func TestSearchWindowsBySchool(t *testing.T) {
+	tests := []struct {
+		school  string
+		query   string
+		windows []models.SearchWindow
+	}{
+		// 默认流派子正换日：2024-06-15 的早子与夜子均为庚戌日丙子时
+		{"legacy", "dayPillar=庚戌 AND hourZhi=子", []models.SearchWindow{
+			{Start: "2024-06-15 00:00", End: "2024-06-15 00:59", Pillars: []string{"甲辰 庚午 庚戌 丙子"}},
+			{Start: "2024-06-15 23:00", End: "2024-06-15 23:59", Pillars: []string{"甲辰 庚午 庚戌 丙子"}},
+		}},
+		// 子初换日：06-14 23 点已入庚戌日，与次日早子连成一段；06-15 23 点属辛亥日
+		{"traditional", "dayPillar=庚戌 AND hourZhi=子", []models.SearchWindow{
+			{Start: "2024-06-14 23:00", End: "2024-06-15 00:59", Pillars: []string{"甲辰 庚午 庚戌 丙子"}},
+		}},
+		// 连续命中的时辰合并为一段，四柱按出现顺序列出
+		{"traditional", "dayPillar=庚戌 AND hourZhi IN (寅,卯)", []models.SearchWindow{
+			{Start: "2024-06-15 03:00", End: "2024-06-15 06:59", Pillars: []string{"甲辰 庚午 庚戌 戊寅", "甲辰 庚午 庚戌 己卯"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		profile, err := GetSchoolProfile(tt.school)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := models.SearchRequest{Query: tt.query, StartDate: "2024-06-14", EndDate: "2024-06-16"}
+		result, err := NewSearchService().Search(req, CalcOptions{Profile: profile})
+		if err != nil {
+			t.Fatalf("%s Search(%q) error: %v", tt.school, tt.query, err)
+		}
+		if result.School != profile.ID() {
+			t.Errorf("%s Search school = %s, want %s", tt.school, result.School, profile.ID())
+		}
+		if len(result.Windows) != len(tt.windows) {
+			t.Fatalf("%s Search(%q) windows = %v, want %v", tt.school, tt.query, result.Windows, tt.windows)
+		}
+		for i, window := range result.Windows {
+			want := tt.windows[i]
+			if window.Start != want.Start || window.End != want.End || len(window.Pillars) != len(want.Pillars) {
+				t.Errorf("%s Search(%q) window %d = %v, want %v", tt.school, tt.query, i, window, want)
+				continue
+			}
+			for j := range want.Pillars {
+				if window.Pillars[j] != want.Pillars[j] {
+					t.Errorf("%s Search(%q) window %d pillars = %v, want %v", tt.school, tt.query, i, window.Pillars, want.Pillars)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestSearchLimit(t *testing.T) {
+	req := models.SearchRequest{Query: "hourZhi=子", StartDate: "2024-06-01", EndDate: "2024-06-30", Limit: 3}
+	result, err := NewSearchService().Search(req, CalcOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Windows) != 3 || !result.Truncated {
+		t.Errorf("Search limit 3: windows = %d truncated = %v, want 3 true", len(result.Windows), result.Truncated)
+	}
+}
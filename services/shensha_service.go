@@ -3,72 +3,479 @@ package services
 import "auspire/models"
 
 // ShenShaService 神煞服务
-type ShenShaService struct{}
+//
+// 神煞由规则表驱动：每条规则声明查法基准（日干、年支、三合局等）、
+// 匹配对象（天干、地支或整柱）、参与匹配的柱位以及出处。
+type ShenShaService struct {
+	rules []ShenShaRule
+}
+
+// ShenShaBasis 神煞查法基准
+type ShenShaBasis string
+
+const (
+	BasisRiGan        ShenShaBasis = "日干"
+	BasisNianGan      ShenShaBasis = "年干"
+	BasisNianZhi      ShenShaBasis = "年支"
+	BasisRiZhi        ShenShaBasis = "日支"
+	BasisYueZhi       ShenShaBasis = "月支"
+	BasisRiZhu        ShenShaBasis = "日柱"
+	BasisNianZhiSanHe ShenShaBasis = "年支三合"
+	BasisRiZhiSanHe   ShenShaBasis = "日支三合"
+)
+
+// ShenShaTarget 神煞匹配对象
+type ShenShaTarget string
+
+const (
+	TargetZhi      ShenShaTarget = "地支"
+	TargetGan      ShenShaTarget = "天干"
+	TargetGanOrZhi ShenShaTarget = "天干或地支"
+	TargetGanZhi   ShenShaTarget = "干支"
+)
+
+// ShenShaRule 神煞规则
+type ShenShaRule struct {
+	Name        string              `json:"name"`
+	Category    string              `json:"category"`            // 吉/凶/中性
+	Description string              `json:"description"`         // 简要含义
+	Bases       []ShenShaBasis      `json:"bases"`               // 查法基准，可多个
+	Target      ShenShaTarget       `json:"target"`              // 匹配对象
+	Positions   []int               `json:"positions,omitempty"` // 参与匹配的柱位（0年 1月 2日 3时），为空表示四柱
+	Table       map[string][]string `json:"table"`               // 基准值 -> 命中值
+	Sources     []string            `json:"sources"`             // 出处
+}
+
+// shenShaMatch 单条神煞命中记录
+type shenShaMatch struct {
+	Rule  ShenShaRule
+	Basis ShenShaBasis
+	Key   string
+	Value string
+}
 
 var (
-	// 神煞对照表
-	shenShaData = map[string]map[string][]string{
-		"甲": {
-			"天乙贵人": {"丑", "未"}, "太极贵人": {"子", "午"}, "文昌贵人": {"巳"},
-			"将星": {"子"}, "华盖": {"戌"}, "咸池": {"酉"}, "驿马": {"寅"}, "灾煞": {"午"},
+	// 神煞规则表
+	shenShaRules = []ShenShaRule{
+		// ---- 吉神 ----
+		{
+			Name:        "天乙贵人",
+			Category:    "吉",
+			Description: "诸神之首，主逢凶化吉、得贵人扶助",
+			Bases:       []ShenShaBasis{BasisRiGan, BasisNianGan},
+			Target:      TargetZhi,
+			// 按「甲戊庚牛羊，乙己鼠猴乡，丙丁猪鸡位，壬癸兔蛇藏，六辛逢马虎」的先后排列，紫微斗数以前者为天魁、后者为天钺
+			Table: map[string][]string{
+				"甲": {"丑", "未"}, "乙": {"子", "申"}, "丙": {"亥", "酉"}, "丁": {"亥", "酉"}, "戊": {"丑", "未"},
+				"己": {"子", "申"}, "庚": {"丑", "未"}, "辛": {"午", "寅"}, "壬": {"卯", "巳"}, "癸": {"卯", "巳"},
+			},
+			Sources: []string{"三命通会·论天乙贵人"},
+		},
+		{
+			Name:        "太极贵人",
+			Category:    "吉",
+			Description: "主聪明好学，喜玄理哲学，晚景安泰",
+			Bases:       []ShenShaBasis{BasisRiGan, BasisNianGan},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"甲": {"子", "午"}, "乙": {"卯", "酉"}, "丙": {"卯", "酉"}, "丁": {"子", "午"}, "戊": {"卯", "酉"},
+				"己": {"子", "午"}, "庚": {"子", "午"}, "辛": {"卯", "酉"}, "壬": {"子", "午"}, "癸": {"卯", "酉"},
+			},
+			Sources: []string{"三命通会·论太极贵人"},
+		},
+		{
+			Name:        "文昌贵人",
+			Category:    "吉",
+			Description: "主聪明才智，利读书考试、文字事业",
+			Bases:       []ShenShaBasis{BasisRiGan, BasisNianGan},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"甲": {"巳"}, "乙": {"午"}, "丙": {"申"}, "丁": {"酉"}, "戊": {"申"},
+				"己": {"酉"}, "庚": {"亥"}, "辛": {"子"}, "壬": {"寅"}, "癸": {"卯"},
+			},
+			Sources: []string{"三命通会·论文昌"},
+		},
+		{
+			Name:        "国印贵人",
+			Category:    "吉",
+			Description: "主掌印信权柄，为人诚实，宜公职",
+			Bases:       []ShenShaBasis{BasisRiGan, BasisNianGan},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"甲": {"戌"}, "乙": {"亥"}, "丙": {"丑"}, "丁": {"寅"}, "戊": {"丑"},
+				"己": {"寅"}, "庚": {"辰"}, "辛": {"巳"}, "壬": {"未"}, "癸": {"申"},
+			},
+			Sources: []string{"三命通会·论国印"},
+		},
+		{
+			Name:        "天德贵人",
+			Category:    "吉",
+			Description: "天地德秀之气，主化凶解厄、心地仁慈",
+			Bases:       []ShenShaBasis{BasisYueZhi},
+			Target:      TargetGanOrZhi,
+			Table: map[string][]string{
+				"寅": {"丁"}, "卯": {"申"}, "辰": {"壬"}, "巳": {"辛"}, "午": {"亥"}, "未": {"甲"},
+				"申": {"癸"}, "酉": {"寅"}, "戌": {"丙"}, "亥": {"乙"}, "子": {"巳"}, "丑": {"庚"},
+			},
+			Sources: []string{"三命通会·论天月德"},
 		},
-		"乙": {
-			"天乙贵人": {"子", "申"}, "太极贵人": {"卯", "酉"}, "文昌贵人": {"午"},
-			"将星": {"酉"}, "华盖": {"未"}, "咸池": {"午"}, "驿马": {"亥"}, "灾煞": {"卯"},
+		{
+			Name:        "天德合",
+			Category:    "吉",
+			Description: "与天德相合，福力稍次于天德",
+			Bases:       []ShenShaBasis{BasisYueZhi},
+			Target:      TargetGanOrZhi,
+			Table: map[string][]string{
+				"寅": {"壬"}, "卯": {"巳"}, "辰": {"丁"}, "巳": {"丙"}, "午": {"寅"}, "未": {"己"},
+				"申": {"戊"}, "酉": {"亥"}, "戌": {"辛"}, "亥": {"庚"}, "子": {"申"}, "丑": {"乙"},
+			},
+			Sources: []string{"三命通会·论天月德"},
 		},
-		"丙": {
-			"天乙贵人": {"亥", "酉"}, "太极贵人": {"卯", "酉"}, "文昌贵人": {"申"},
-			"将星": {"午"}, "华盖": {"辰"}, "咸池": {"卯"}, "驿马": {"巳"}, "灾煞": {"子"},
+		{
+			Name:        "月德贵人",
+			Category:    "吉",
+			Description: "太阴之德，主一生少病、逢凶化吉",
+			Bases:       []ShenShaBasis{BasisYueZhi},
+			Target:      TargetGan,
+			Table: map[string][]string{
+				"寅": {"丙"}, "午": {"丙"}, "戌": {"丙"}, "申": {"壬"}, "子": {"壬"}, "辰": {"壬"},
+				"亥": {"甲"}, "卯": {"甲"}, "未": {"甲"}, "巳": {"庚"}, "酉": {"庚"}, "丑": {"庚"},
+			},
+			Sources: []string{"三命通会·论天月德"},
 		},
-		"丁": {
-			"天乙贵人": {"亥", "酉"}, "太极贵人": {"子", "午"}, "文昌贵人": {"酉"},
-			"将星": {"卯"}, "华盖": {"丑"}, "咸池": {"子"}, "驿马": {"申"}, "灾煞": {"酉"},
+		{
+			Name:        "月德合",
+			Category:    "吉",
+			Description: "与月德相合，主平安顺遂",
+			Bases:       []ShenShaBasis{BasisYueZhi},
+			Target:      TargetGan,
+			Table: map[string][]string{
+				"寅": {"辛"}, "午": {"辛"}, "戌": {"辛"}, "申": {"丁"}, "子": {"丁"}, "辰": {"丁"},
+				"亥": {"己"}, "卯": {"己"}, "未": {"己"}, "巳": {"乙"}, "酉": {"乙"}, "丑": {"乙"},
+			},
+			Sources: []string{"三命通会·论天月德"},
 		},
-		"戊": {
-			"天乙贵人": {"丑", "未"}, "太极贵人": {"卯", "酉"}, "文昌贵人": {"申"},
-			"将星": {"午"}, "华盖": {"辰"}, "咸池": {"卯"}, "驿马": {"巳"}, "灾煞": {"子"},
+		{
+			Name:        "禄神",
+			Category:    "吉",
+			Description: "日干临官之地，主衣禄丰足、身体强健",
+			Bases:       []ShenShaBasis{BasisRiGan},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"甲": {"寅"}, "乙": {"卯"}, "丙": {"巳"}, "丁": {"午"}, "戊": {"巳"},
+				"己": {"午"}, "庚": {"申"}, "辛": {"酉"}, "壬": {"亥"}, "癸": {"子"},
+			},
+			Sources: []string{"渊海子平·论禄"},
 		},
-		"己": {
-			"天乙贵人": {"子", "申"}, "太极贵人": {"子", "午"}, "文昌贵人": {"酉"},
-			"将星": {"卯"}, "华盖": {"丑"}, "咸池": {"子"}, "驿马": {"申"}, "灾煞": {"酉"},
+		{
+			Name:        "将星",
+			Category:    "吉",
+			Description: "三合中神，主有组织领导之才、掌权",
+			Bases:       []ShenShaBasis{BasisNianZhiSanHe, BasisRiZhiSanHe},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"申子辰": {"子"}, "寅午戌": {"午"}, "巳酉丑": {"酉"}, "亥卯未": {"卯"},
+			},
+			Sources: []string{"三命通会·论将星华盖"},
 		},
-		"庚": {
-			"天乙贵人": {"丑", "未"}, "太极贵人": {"子", "午"}, "文昌贵人": {"亥"},
-			"将星": {"子"}, "华盖": {"戌"}, "咸池": {"酉"}, "驿马": {"寅"}, "灾煞": {"午"},
+		{
+			Name:        "金舆",
+			Category:    "吉",
+			Description: "禄前二位，主富贵、得配偶之助",
+			Bases:       []ShenShaBasis{BasisRiGan},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"甲": {"辰"}, "乙": {"巳"}, "丙": {"未"}, "丁": {"申"}, "戊": {"未"},
+				"己": {"申"}, "庚": {"戌"}, "辛": {"亥"}, "壬": {"丑"}, "癸": {"寅"},
+			},
+			Sources: []string{"三命通会·论金舆"},
 		},
-		"辛": {
-			"天乙贵人": {"寅", "午"}, "太极贵人": {"卯", "酉"}, "文昌贵人": {"子"},
-			"将星": {"酉"}, "华盖": {"未"}, "咸池": {"午"}, "驿马": {"亥"}, "灾煞": {"卯"},
+		{
+			Name:        "学堂",
+			Category:    "吉",
+			Description: "日干长生之地，主聪慧好学、学业有成",
+			Bases:       []ShenShaBasis{BasisRiGan},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"甲": {"亥"}, "乙": {"午"}, "丙": {"寅"}, "丁": {"酉"}, "戊": {"寅"},
+				"己": {"酉"}, "庚": {"巳"}, "辛": {"子"}, "壬": {"申"}, "癸": {"卯"},
+			},
+			Sources: []string{"三命通会·论学堂词馆"},
 		},
-		"壬": {
-			"天乙贵人": {"卯", "巳"}, "太极贵人": {"子", "午"}, "文昌贵人": {"寅"},
-			"将星": {"午"}, "华盖": {"辰"}, "咸池": {"卯"}, "驿马": {"巳"}, "灾煞": {"子"},
+		{
+			Name:        "词馆",
+			Category:    "吉",
+			Description: "主文采出众，利文职、著述",
+			Bases:       []ShenShaBasis{BasisRiGan},
+			Target:      TargetGanZhi,
+			Table: map[string][]string{
+				"甲": {"庚寅"}, "乙": {"辛卯"}, "丙": {"乙巳"}, "丁": {"戊午"}, "戊": {"丁巳"},
+				"己": {"庚午"}, "庚": {"壬申"}, "辛": {"癸酉"}, "壬": {"癸亥"}, "癸": {"壬戌"},
+			},
+			Sources: []string{"三命通会·论学堂词馆"},
 		},
-		"癸": {
-			"天乙贵人": {"卯", "巳"}, "太极贵人": {"卯", "酉"}, "文昌贵人": {"卯"},
-			"将星": {"卯"}, "华盖": {"丑"}, "咸池": {"子"}, "驿马": {"申"}, "灾煞": {"酉"},
+		{
+			Name:        "天医",
+			Category:    "吉",
+			Description: "月支前一位，主健康，宜医药、心理行业",
+			Bases:       []ShenShaBasis{BasisYueZhi},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"寅": {"丑"}, "卯": {"寅"}, "辰": {"卯"}, "巳": {"辰"}, "午": {"巳"}, "未": {"午"},
+				"申": {"未"}, "酉": {"申"}, "戌": {"酉"}, "亥": {"戌"}, "子": {"亥"}, "丑": {"子"},
+			},
+			Sources: []string{"三命通会·论天医"},
+		},
+		{
+			Name:        "红鸾",
+			Category:    "吉",
+			Description: "主婚姻喜庆，逢之多有姻缘",
+			Bases:       []ShenShaBasis{BasisNianZhi},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"子": {"卯"}, "丑": {"寅"}, "寅": {"丑"}, "卯": {"子"}, "辰": {"亥"}, "巳": {"戌"},
+				"午": {"酉"}, "未": {"申"}, "申": {"未"}, "酉": {"午"}, "戌": {"巳"}, "亥": {"辰"},
+			},
+			Sources: []string{"星平会海·论红鸾天喜"},
+		},
+		{
+			Name:        "天喜",
+			Category:    "吉",
+			Description: "红鸾对宫，主喜庆、添丁",
+			Bases:       []ShenShaBasis{BasisNianZhi},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"子": {"酉"}, "丑": {"申"}, "寅": {"未"}, "卯": {"午"}, "辰": {"巳"}, "巳": {"辰"},
+				"午": {"卯"}, "未": {"寅"}, "申": {"丑"}, "酉": {"子"}, "戌": {"亥"}, "亥": {"戌"},
+			},
+			Sources: []string{"星平会海·论红鸾天喜"},
+		},
+		{
+			Name:        "天赦",
+			Category:    "吉",
+			Description: "四时专气生育之日，主逢凶化吉、罪过得赦",
+			Bases:       []ShenShaBasis{BasisYueZhi},
+			Target:      TargetGanZhi,
+			Positions:   []int{2},
+			Table: map[string][]string{
+				"寅": {"戊寅"}, "卯": {"戊寅"}, "辰": {"戊寅"}, "巳": {"甲午"}, "午": {"甲午"}, "未": {"甲午"},
+				"申": {"戊申"}, "酉": {"戊申"}, "戌": {"戊申"}, "亥": {"甲子"}, "子": {"甲子"}, "丑": {"甲子"},
+			},
+			Sources: []string{"三命通会·论天赦"},
+		},
+
+		// ---- 中性 ----
+		{
+			Name:        "华盖",
+			Category:    "中性",
+			Description: "三合墓库，主孤高聪慧、好艺术宗教",
+			Bases:       []ShenShaBasis{BasisNianZhiSanHe, BasisRiZhiSanHe},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"申子辰": {"辰"}, "寅午戌": {"戌"}, "巳酉丑": {"丑"}, "亥卯未": {"未"},
+			},
+			Sources: []string{"三命通会·论将星华盖"},
+		},
+		{
+			Name:        "驿马",
+			Category:    "中性",
+			Description: "三合首冲，主奔波走动、迁移变化",
+			Bases:       []ShenShaBasis{BasisNianZhiSanHe, BasisRiZhiSanHe},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"申子辰": {"寅"}, "寅午戌": {"申"}, "巳酉丑": {"亥"}, "亥卯未": {"巳"},
+			},
+			Sources: []string{"三命通会·论驿马"},
+		},
+		{
+			Name:        "咸池",
+			Category:    "中性",
+			Description: "又称桃花，主人缘异性缘，过旺则主风流",
+			Bases:       []ShenShaBasis{BasisNianZhiSanHe, BasisRiZhiSanHe},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"申子辰": {"酉"}, "寅午戌": {"卯"}, "巳酉丑": {"午"}, "亥卯未": {"子"},
+			},
+			Sources: []string{"三命通会·论咸池"},
+		},
+		{
+			Name:        "红艳煞",
+			Category:    "中性",
+			Description: "主多情风流、异性缘佳",
+			Bases:       []ShenShaBasis{BasisRiGan},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"甲": {"午"}, "乙": {"午"}, "丙": {"寅"}, "丁": {"未"}, "戊": {"辰"},
+				"己": {"辰"}, "庚": {"戌"}, "辛": {"酉"}, "壬": {"子"}, "癸": {"申"},
+			},
+			Sources: []string{"三命通会·论红艳"},
+		},
+		{
+			Name:        "魁罡",
+			Category:    "中性",
+			Description: "主性格刚烈、聪明果断，大富大贵或大起大落",
+			Bases:       []ShenShaBasis{BasisRiZhu},
+			Target:      TargetGanZhi,
+			Positions:   []int{2},
+			Table:       selfTable("庚辰", "庚戌", "壬辰", "戊戌"),
+			Sources:     []string{"三命通会·论魁罡"},
+		},
+
+		// ---- 凶神 ----
+		{
+			Name:        "羊刃",
+			Category:    "凶",
+			Description: "阳干取禄前一位（甲禄寅刃卯），阴干取禄后一位（乙禄卯刃寅），主刚强冲动，易有血光是非",
+			Bases:       []ShenShaBasis{BasisRiGan},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"甲": {"卯"}, "乙": {"寅"}, "丙": {"午"}, "丁": {"巳"}, "戊": {"午"},
+				"己": {"巳"}, "庚": {"酉"}, "辛": {"申"}, "壬": {"子"}, "癸": {"亥"},
+			},
+			Sources: []string{"渊海子平·论羊刃"},
+		},
+		{
+			Name:        "劫煞",
+			Category:    "凶",
+			Description: "三合绝地，主意外破财、是非",
+			Bases:       []ShenShaBasis{BasisNianZhiSanHe, BasisRiZhiSanHe},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"申子辰": {"巳"}, "寅午戌": {"亥"}, "巳酉丑": {"寅"}, "亥卯未": {"申"},
+			},
+			Sources: []string{"三命通会·论劫煞灾煞"},
+		},
+		{
+			Name:        "灾煞",
+			Category:    "凶",
+			Description: "冲将星之位，主灾祸、血光",
+			Bases:       []ShenShaBasis{BasisNianZhiSanHe, BasisRiZhiSanHe},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"申子辰": {"午"}, "寅午戌": {"子"}, "巳酉丑": {"卯"}, "亥卯未": {"酉"},
+			},
+			Sources: []string{"三命通会·论劫煞灾煞"},
+		},
+		{
+			Name:        "亡神",
+			Category:    "凶",
+			Description: "三合临官之地，主心机深沉、易招官非",
+			Bases:       []ShenShaBasis{BasisNianZhiSanHe, BasisRiZhiSanHe},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"申子辰": {"亥"}, "寅午戌": {"巳"}, "巳酉丑": {"申"}, "亥卯未": {"寅"},
+			},
+			Sources: []string{"三命通会·论亡神"},
+		},
+		{
+			Name:        "孤辰",
+			Category:    "凶",
+			Description: "主孤独，男命忌之，六亲缘薄",
+			Bases:       []ShenShaBasis{BasisNianZhi},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"亥": {"寅"}, "子": {"寅"}, "丑": {"寅"}, "寅": {"巳"}, "卯": {"巳"}, "辰": {"巳"},
+				"巳": {"申"}, "午": {"申"}, "未": {"申"}, "申": {"亥"}, "酉": {"亥"}, "戌": {"亥"},
+			},
+			Sources: []string{"三命通会·论孤辰寡宿"},
+		},
+		{
+			Name:        "寡宿",
+			Category:    "凶",
+			Description: "主孤寡，女命忌之，婚姻多阻",
+			Bases:       []ShenShaBasis{BasisNianZhi},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"亥": {"戌"}, "子": {"戌"}, "丑": {"戌"}, "寅": {"丑"}, "卯": {"丑"}, "辰": {"丑"},
+				"巳": {"辰"}, "午": {"辰"}, "未": {"辰"}, "申": {"未"}, "酉": {"未"}, "戌": {"未"},
+			},
+			Sources: []string{"三命通会·论孤辰寡宿"},
+		},
+		{
+			Name:        "勾绞",
+			Category:    "凶",
+			Description: "年支前后三位，主口舌纠纷、牵连是非",
+			Bases:       []ShenShaBasis{BasisNianZhi},
+			Target:      TargetZhi,
+			Table: map[string][]string{
+				"子": {"卯", "酉"}, "丑": {"辰", "戌"}, "寅": {"巳", "亥"}, "卯": {"午", "子"},
+				"辰": {"未", "丑"}, "巳": {"申", "寅"}, "午": {"酉", "卯"}, "未": {"戌", "辰"},
+				"申": {"亥", "巳"}, "酉": {"子", "午"}, "戌": {"丑", "未"}, "亥": {"寅", "申"},
+			},
+			Sources: []string{"三命通会·论勾绞"},
+		},
+		{
+			Name:        "十恶大败",
+			Category:    "凶",
+			Description: "日柱禄入空亡，主不善理财、家业难守",
+			Bases:       []ShenShaBasis{BasisRiZhu},
+			Target:      TargetGanZhi,
+			Positions:   []int{2},
+			Table:       selfTable("甲辰", "乙巳", "丙申", "丁亥", "戊戌", "己丑", "庚辰", "辛巳", "壬申", "癸亥"),
+			Sources:     []string{"三命通会·论十恶大败"},
+		},
+		{
+			Name:        "阴差阳错",
+			Category:    "凶",
+			Description: "主婚姻不顺、与外家不睦",
+			Bases:       []ShenShaBasis{BasisRiZhu},
+			Target:      TargetGanZhi,
+			Positions:   []int{2},
+			Table:       selfTable("丙子", "丁丑", "戊寅", "辛卯", "壬辰", "癸巳", "丙午", "丁未", "戊申", "辛酉", "壬戌", "癸亥"),
+			Sources:     []string{"三命通会·论阴差阳错"},
+		},
+		{
+			Name:        "孤鸾煞",
+			Category:    "凶",
+			Description: "主婚姻孤寡、夫妻缘薄",
+			Bases:       []ShenShaBasis{BasisRiZhu},
+			Target:      TargetGanZhi,
+			Positions:   []int{2},
+			Table:       selfTable("乙巳", "丁巳", "辛亥", "戊申", "甲寅", "壬子", "丙午", "戊午"),
+			Sources:     []string{"三命通会·论孤鸾"},
 		},
 	}
 )
 
+// shenShaTable 按名称取传统神煞规则的查表，供紫微斗数、奇门、六壬等复用
+func shenShaTable(name string) map[string][]string {
+	for _, rule := range shenShaRules {
+		if rule.Name == name {
+			return rule.Table
+		}
+	}
+	return nil
+}
+
+// selfTable 构造以干支自身为键的规则表（用于按日柱直接成立的神煞）
+func selfTable(ganZhiList ...string) map[string][]string {
+	table := make(map[string][]string, len(ganZhiList))
+	for _, ganZhi := range ganZhiList {
+		table[ganZhi] = []string{ganZhi}
+	}
+	return table
+}
+
 func NewShenShaService() *ShenShaService {
-	return &ShenShaService{}
+	return newShenShaService(defaultSchoolProfile())
 }
 
-// Calculate 计算神煞
-func (s *ShenShaService) Calculate(dayGan string, bazi []models.BaziColumn) map[string]string {
-	result := make(map[string]string)
-
-	if shenShaConfig, exists := shenShaData[dayGan]; exists {
-		for shenShaName, zhiList := range shenShaConfig {
-			for _, column := range bazi {
-				for _, zhi := range zhiList {
-					if column.Zhi == zhi {
-						result[shenShaName] = zhi
-						break
-					}
-				}
-			}
+// newShenShaService 按流派的神煞规则表创建神煞服务
+func newShenShaService(profile *SchoolProfile) *ShenShaService {
+	return &ShenShaService{
+		rules: profile.ShenShaRules,
+	}
+}
+
+// Calculate 计算整盘神煞，结果标注所在柱
+func (s *ShenShaService) Calculate(bazi []models.BaziColumn) []models.ShenShaItem {
+	columnNames := []string{"年柱", "月柱", "日柱", "时柱"}
+	result := []models.ShenShaItem{}
+
+	for i := range bazi {
+		for _, item := range s.CalculateForColumn(bazi, i) {
+			item.Pillar = columnNames[i]
+			result = append(result, item)
 		}
 	}
 
@@ -76,24 +483,112 @@ func (s *ShenShaService) Calculate(dayGan string, bazi []models.BaziColumn) map[
 }
 
 // CalculateForColumn 计算单个柱子的神煞
-func (s *ShenShaService) CalculateForColumn(dayGan string, column models.BaziColumn) map[string]string {
-	result := make(map[string]string)
-
-	if shenShaConfig, exists := shenShaData[dayGan]; exists {
-		for shenShaName, zhiList := range shenShaConfig {
-			for _, zhi := range zhiList {
-				if column.Zhi == zhi {
-					result[shenShaName] = zhi
-					break
-				}
-			}
+//
+// 同一神煞由多个基准同时命中时合并为一项，基准以"、"连接。
+func (s *ShenShaService) CalculateForColumn(bazi []models.BaziColumn, index int) []models.ShenShaItem {
+	result := []models.ShenShaItem{}
+	positions := make(map[string]int)
+
+	for _, m := range s.matchColumn(bazi, index) {
+		if pos, exists := positions[m.Rule.Name]; exists {
+			result[pos].Basis += "、" + string(m.Basis)
+			continue
 		}
+		positions[m.Rule.Name] = len(result)
+		result = append(result, models.ShenShaItem{
+			Name:        m.Rule.Name,
+			Category:    m.Rule.Category,
+			Description: m.Rule.Description,
+			Basis:       string(m.Basis),
+			Match:       m.Value,
+		})
 	}
 
 	return result
 }
 
-// GetAllShenSha 获取所有神煞数据
-func (s *ShenShaService) GetAllShenSha() map[string]map[string][]string {
-	return shenShaData
-}
\ No newline at end of file
+// matchColumn 按规则逐条匹配指定柱，返回每个命中的基准
+func (s *ShenShaService) matchColumn(bazi []models.BaziColumn, index int) []shenShaMatch {
+	matches := []shenShaMatch{}
+	column := bazi[index]
+
+	for _, rule := range s.rules {
+		if !rule.appliesTo(index) {
+			continue
+		}
+		for _, basis := range rule.Bases {
+			key := shenShaBasisKey(basis, bazi)
+			if value, ok := rule.matchValue(key, column); ok {
+				matches = append(matches, shenShaMatch{Rule: rule, Basis: basis, Key: key, Value: value})
+			}
+		}
+	}
+
+	return matches
+}
+
+// appliesTo 判断规则是否作用于指定柱位
+func (r ShenShaRule) appliesTo(index int) bool {
+	if len(r.Positions) == 0 {
+		return true
+	}
+	for _, p := range r.Positions {
+		if p == index {
+			return true
+		}
+	}
+	return false
+}
+
+// matchValue 以基准值查表，判断本柱是否命中
+func (r ShenShaRule) matchValue(key string, column models.BaziColumn) (string, bool) {
+	for _, target := range r.Table[key] {
+		switch r.Target {
+		case TargetZhi:
+			if column.Zhi == target {
+				return target, true
+			}
+		case TargetGan:
+			if column.Gan == target {
+				return target, true
+			}
+		case TargetGanOrZhi:
+			if column.Gan == target || column.Zhi == target {
+				return target, true
+			}
+		case TargetGanZhi:
+			if column.Gan+column.Zhi == target {
+				return target, true
+			}
+		}
+	}
+	return "", false
+}
+
+// shenShaBasisKey 取查法基准在命局中的值
+func shenShaBasisKey(basis ShenShaBasis, bazi []models.BaziColumn) string {
+	switch basis {
+	case BasisRiGan:
+		return bazi[2].Gan
+	case BasisNianGan:
+		return bazi[0].Gan
+	case BasisNianZhi:
+		return bazi[0].Zhi
+	case BasisRiZhi:
+		return bazi[2].Zhi
+	case BasisYueZhi:
+		return bazi[1].Zhi
+	case BasisRiZhu:
+		return bazi[2].Gan + bazi[2].Zhi
+	case BasisNianZhiSanHe:
+		return diZhiSanHe[bazi[0].Zhi]
+	case BasisRiZhiSanHe:
+		return diZhiSanHe[bazi[2].Zhi]
+	}
+	return ""
+}
+
+// GetAllShenSha 获取所有神煞规则
+func (s *ShenShaService) GetAllShenSha() []ShenShaRule {
+	return s.rules
+}
@@ -10,69 +10,82 @@
 //
 // The implementation follows traditional rules while ensuring accuracy through:
 // - Proper Jie Qi (Solar Terms) based month pillar calculation
-// - Correct Yang/Yin Gan sequencing for twelve longevity
+// - A single twelve longevity lookup with selectable convention
 // - Accurate藏干 mappings for each Earthly Branch
 //
 // Each service is modularized for maintainability and extensibility.
 package services
 
-// ShiErZhangSheng 十二长生表 (Twelve Longevity Cycle Table)
-//
-// This table defines the twelve stages of existence for each Heavenly Stem.
-// The concept reflects the natural cycle of growth and decline in Chinese metaphysics.
-//
-// Key Principles:
-// 1. Yang Heavenly Stems (甲, 丙, 戊, 庚, 壬) follow顺行 (forward sequence)
-// 2. Yin Heavenly Stems (乙, 丁, 己, 辛, 癸) follow逆行 (reverse sequence)
-// 3. Each stem has its own unique cycle progression through the twelve Earthly Branches
-//
-// The twelve stages represent:
-// 1. 长生 (Chang Sheng) - Longevity/Birth - Beginning of growth
-// 2. 沐浴 (Mu Yu) - Bathing - Cleansing and preparation
-// 3. 冠带 (Guan Dai) - Crown/Wearing - Maturation begins
-// 4. 临官 (Lin Guan) - Approaching Officerdom - Near peak strength
-// 5. 帝旺 (Di Wang) - Imperial Prosperity - Peak power
-// 6. 衰 (Shuai) - Decline - Beginning of weakening
-// 7. 病 (Bing) - Sickness - Further deterioration
-// 8. 死 (Si) - Death - End of current cycle
-// 9. 墓 (Mu) - Tomb/Storage - Energy stored away
-// 10. 绝 (Jue) - Severance/Cutting off - Complete separation
-// 11. 胎 (Tai) - Conception/Fetus - New beginning forming
-// 12. 养 (Yang) - Nurturing - Preparation for rebirth
-var ShiErZhangSheng = map[string][]string{
-	// Yang Heavenly Stems (Forward Sequence)
-	//
-	// 甲木 (Jia Wood) - Begins at亥 (Hai) - Water nourishes Wood
-	"甲": {"亥", "子", "丑", "寅", "卯", "辰", "巳", "午", "未", "申", "酉", "戌"},
-	
-	// 丙火 (Bing Fire) - Begins at寅 (Yin) - Wood fuels Fire
-	"丙": {"寅", "卯", "辰", "巳", "午", "未", "申", "酉", "戌", "亥", "子", "丑"},
-	
-	// 戊土 (Wu Earth) - Follows same as Bing Fire
-	"戊": {"寅", "卯", "辰", "巳", "午", "未", "申", "酉", "戌", "亥", "子", "丑"},
-	
-	// 庚金 (Geng Metal) - Begins at巳 (Si) - Earth produces Metal
-	"庚": {"巳", "午", "未", "申", "酉", "戌", "亥", "子", "丑", "寅", "卯", "辰"},
-	
-	// 壬水 (Ren Water) - Begins at申 (Shen) - Metal generates Water
-	"壬": {"申", "酉", "戌", "亥", "子", "丑", "寅", "卯", "辰", "巳", "午", "未"},
+// ChangShengMode 十二长生取法 (Twelve Longevity Convention)
+//
+// 十二长生历来有两种取法，排盘各处必须使用同一取法，否则同一命盘的星运、
+// 自坐与月令状态会互相矛盾：
+//   - 五行同生同死：以五行论长生，阴阳干同五行者共用阳干的顺行序列
+//     （如甲乙木均长生于亥）
+//   - 阳生阴死：阳干顺行；阴干长生于同五行阳干的死地并逆行
+//     （如甲长生于亥、死于午，乙则长生于午、死于亥），即阳顺阴逆
+//
+// 土的长生另由流派决定随火（寄生于寅）或随水（寄生于申）。
+type ChangShengMode string
+
+const (
+	// ChangShengWuXing 五行同生同死
+	ChangShengWuXing ChangShengMode = "五行同生同死"
+	// ChangShengYangShengYinSi 阳生阴死（阳顺阴逆）
+	ChangShengYangShengYinSi ChangShengMode = "阳生阴死"
+)
+
+// ChangShengStart 各五行（阳干）的长生之地
+//
+// 土不在表中，查询时按流派换作火或水。
+var ChangShengStart = map[string]string{
+	"木": "亥", // 甲木长生于亥 - Water nourishes Wood
+	"火": "寅", // 丙火长生于寅 - Wood fuels Fire
+	"金": "巳", // 庚金长生于巳 - Earth produces Metal
+	"水": "申", // 壬水长生于申 - Metal generates Water
+}
+
+// IsValidChangShengMode 判断十二长生取法是否受支持
+func IsValidChangShengMode(mode ChangShengMode) bool {
+	return mode == ChangShengWuXing || mode == ChangShengYangShengYinSi
+}
+
+// ZhangShengPosition 按指定取法获取天干在地支中的十二长生状态
+//
+// Parameters:
+//   - gan: The Heavenly Stem to analyze (e.g., "甲", "乙", "丙")
+//   - zhi: The Earthly Branch position (e.g., "子", "丑", "寅")
+//   - mode: 十二长生取法（五行同生同死或阳生阴死）
+//   - tuFollows: 土所随的五行，"火"或"水"
+//
+// Returns:
+//   The corresponding longevity stage name (e.g., "长生", "帝旺", "墓")
+//   Empty string if the stem, branch or mode is invalid
+//
+// Example:
+//   ZhangShengPosition("乙", "亥", ChangShengWuXing, "火") returns "长生"
+//   ZhangShengPosition("乙", "亥", ChangShengYangShengYinSi, "火") returns "死"
+func ZhangShengPosition(gan, zhi string, mode ChangShengMode, tuFollows string) string {
+	if !IsValidChangShengMode(mode) {
+		return ""
+	}
+
+	wuXing := tianGanWuXing[gan]
+	if wuXing == "土" {
+		wuXing = tuFollows
+	}
+	start, exists := ChangShengStart[wuXing]
+	startIndex, zhiIndex := indexOf(diZhi, start), indexOf(diZhi, zhi)
+	if !exists || startIndex < 0 || zhiIndex < 0 {
+		return ""
+	}
 
-	// Yin Heavenly Stems (Reverse Sequence)
-	//
-	// 乙木 (Yi Wood) - Begins at午 (Wu) - Fire nourishes Wood in reverse
-	"乙": {"午", "巳", "辰", "卯", "寅", "丑", "子", "亥", "戌", "酉", "申", "未"},
-	
-	// 丁火 (Ding Fire) - Begins at酉 (You) - Metal generates Fire in reverse
-	"丁": {"酉", "申", "未", "午", "巳", "辰", "卯", "寅", "丑", "子", "亥", "戌"},
-	
-	// 己土 (Ji Earth) - Follows same as Ding Fire
-	"己": {"酉", "申", "未", "午", "巳", "辰", "卯", "寅", "丑", "子", "亥", "戌"},
-	
-	// 辛金 (Xin Metal) - Begins at子 (Zi) - Water generates Metal in reverse
-	"辛": {"子", "亥", "戌", "酉", "申", "未", "午", "巳", "辰", "卯", "寅", "丑"},
-	
-	// 癸水 (Gui Water) - Begins at卯 (Mao) - Wood generates Water in reverse
-	"癸": {"卯", "寅", "丑", "子", "亥", "戌", "酉", "申", "未", "午", "巳", "辰"},
+	if mode == ChangShengYangShengYinSi && IsYinGan(gan) {
+		// 阴干长生于阳干之死地（顺数第八位），此后逆行
+		yinStart := (startIndex + 7) % 12
+		return ZhangShengNames[(yinStart-zhiIndex+12)%12]
+	}
+	return ZhangShengNames[(zhiIndex-startIndex+12)%12]
 }
 
 // ZhangShengNames 十二长生名称 (Twelve Longevity Stage Names)
@@ -103,39 +116,6 @@ var YangGan = []string{"甲", "丙", "戊", "庚", "壬"}
 // YinGan 阴干列表 (Yin Heavenly Stems)
 var YinGan = []string{"乙", "丁", "己", "辛", "癸"}
 
-// GetZhangShengPosition 获取天干在地支中的长生位置
-//
-// This function determines what stage of the twelve longevity cycle a particular
-// Heavenly Stem is in when placed in a specific Earthly Branch.
-//
-// Parameters:
-//   - tianGan: The Heavenly Stem to analyze (e.g., "甲", "乙", "丙")
-//   - diZhi: The Earthly Branch position (e.g., "子", "丑", "寅")
-//
-// Returns:
-//   The corresponding longevity stage name (e.g., "长生", "帝旺", "墓")
-//   Empty string if the stem or branch is invalid
-//
-// Example:
-//   GetZhangShengPosition("甲", "亥") returns "长生"
-//   GetZhangShengPosition("乙", "午") returns "长生"
-func GetZhangShengPosition(tianGan, diZhi string) string {
-	// Check if the Heavenly Stem is valid
-	shengPositions, exists := ShiErZhangSheng[tianGan]
-	if !exists {
-		return ""
-	}
-	
-	// Find the position of the Earthly Branch in the stem's longevity sequence
-	for i, zhi := range shengPositions {
-		if zhi == diZhi {
-			return ZhangShengNames[i]
-		}
-	}
-	
-	return ""
-}
-
 // IsYangGan 判断是否为阳干
 //
 // Determines if a given Heavenly Stem is classified as Yang.
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"strings"
+)
+
+// ShiShenService 十神统计与性格分析服务
+//
+// 统计四柱天干（日干除外）及地支藏干中各十神的出现次数与加权力量，
+// 区分透出与藏而不透，标注过旺、缺失及常见组合，并映射到性格特质库，
+// 为综合分析提供不依赖大模型的确定性性格结论。十神一律由各柱干支按流派藏干表推算。
+type ShiShenService struct {
+	profile        *SchoolProfile
+	zhuXingService *ZhuXingService
+	cangGanService *CangGanService
+}
+
+// shiShenTrait 十神性格特质
+type shiShenTrait struct {
+	positive []string
+	negative []string
+}
+
+var (
+	// 十神展示顺序
+	shiShenOrder = []string{"比肩", "劫财", "食神", "伤官", "偏财", "正财", "七杀", "正官", "偏印", "正印"}
+
+	// 十神分组（同类十神合论缺失）
+	shiShenGroups = [][3]string{
+		{"比劫", "比肩", "劫财"},
+		{"食伤", "食神", "伤官"},
+		{"财星", "偏财", "正财"},
+		{"官杀", "七杀", "正官"},
+		{"印星", "偏印", "正印"},
+	}
+
+	// 十神性格特质库（透出或力量显著时取用）
+	shiShenTraits = map[string]shiShenTrait{
+		"比肩": {[]string{"独立自主", "意志坚定", "重义气"}, []string{"固执己见", "好胜", "不善合作"}},
+		"劫财": {[]string{"热情豪爽", "行动力强", "善交际"}, []string{"冲动", "好争", "易破财"}},
+		"食神": {[]string{"温和宽厚", "有艺术品味", "乐观知足"}, []string{"懒散", "贪图享受", "进取心不足"}},
+		"伤官": {[]string{"聪明灵巧", "才华出众", "富创造力"}, []string{"恃才傲物", "言语尖刻", "叛逆"}},
+		"偏财": {[]string{"慷慨大方", "人缘好", "商业嗅觉敏锐"}, []string{"轻财", "好投机", "用钱无度"}},
+		"正财": {[]string{"勤俭踏实", "重信守诺", "务实"}, []string{"保守", "吝啬", "缺乏魄力"}},
+		"七杀": {[]string{"果断勇敢", "有魄力", "能担当"}, []string{"性急暴躁", "好斗", "压力大"}},
+		"正官": {[]string{"端正守纪", "有责任感", "重名誉"}, []string{"拘谨刻板", "墨守成规", "顾虑多"}},
+		"偏印": {[]string{"机敏多思", "领悟力强", "有特殊才艺"}, []string{"孤僻多疑", "冷漠", "虎头蛇尾"}},
+		"正印": {[]string{"仁慈宽厚", "好学", "有涵养"}, []string{"依赖心重", "懒于行动", "缺乏主见"}},
+	}
+
+	// 十神分组缺失的特质
+	shiShenAbsenceTraits = map[string]shiShenTrait{
+		"比劫": {[]string{"随和", "不与人争"}, []string{"自信与竞争意识不足", "少得同辈助力"}},
+		"食伤": {[]string{"稳重少言"}, []string{"表达能力较弱", "才华难以展现"}},
+		"财星": {[]string{"淡泊物欲"}, []string{"理财观念薄弱", "对钱财缺乏敏感"}},
+		"官杀": {[]string{"自由不羁", "不受拘束"}, []string{"缺乏约束与自律", "不喜受管"}},
+		"印星": {[]string{"独立", "不依赖他人"}, []string{"少得长辈庇护", "学习耐性不足"}},
+	}
+)
+
+const (
+	// 天干透出计分，藏干按其力量占比计分
+	shiShenStemWeight = 100
+	// 月令藏干力量加倍
+	shiShenYueLingFactor = 2
+)
+
+func NewShiShenService() *ShiShenService {
+	return newShiShenService(defaultSchoolProfile())
+}
+
+// newShiShenService 按流派创建十神统计服务，藏干及其力量占比取该流派的藏干表
+func newShiShenService(profile *SchoolProfile) *ShiShenService {
+	return &ShiShenService{
+		profile:        profile,
+		zhuXingService: NewZhuXingService(),
+		cangGanService: newCangGanService(profile),
+	}
+}
+
+// withProfile 返回绑定指定流派的服务实例，流派相同时返回自身
+func (s *ShiShenService) withProfile(profile *SchoolProfile) *ShiShenService {
+	if profile == s.profile {
+		return s
+	}
+	return newShiShenService(profile)
+}
+
+// Analyze 十神统计与性格分析
+//
+// 十神以日干为我，由各柱天干及流派藏干表推算，不采用调用方传入的主星、副星。
+// 力量以天干每处计 100、藏干按力量占比计分，月支藏干加倍：
+// 0 为无，不足 60 为弱，不足 180 为中，其余为旺。
+func (s *ShiShenService) Analyze(bazi []models.BaziColumn, opts CalcOptions) *models.ShiShenProfile {
+	s = s.withProfile(opts.profile())
+
+	stats := map[string]*models.ShiShenStat{}
+	for _, shiShen := range shiShenOrder {
+		stats[shiShen] = &models.ShiShenStat{ShiShen: shiShen}
+	}
+
+	dayGan := bazi[2].Gan
+	for i, column := range bazi {
+		if stat, exists := stats[s.zhuXingService.Calculate(dayGan, column.Gan)]; exists && i != 2 {
+			stat.StemCount++
+			stat.Score += shiShenStemWeight
+		}
+		for _, item := range s.cangGanService.Items(column.Zhi) {
+			stat, exists := stats[s.zhuXingService.Calculate(dayGan, item.Gan)]
+			if !exists {
+				continue
+			}
+			stat.HiddenCount++
+			if i == 1 {
+				stat.Score += item.Weight * shiShenYueLingFactor
+			} else {
+				stat.Score += item.Weight
+			}
+		}
+	}
+
+	profile := &models.ShiShenProfile{
+		Stats:  []models.ShiShenStat{},
+		Flags:  []string{},
+		Traits: []models.ShiShenTrait{},
+	}
+	for _, shiShen := range shiShenOrder {
+		stat := stats[shiShen]
+		stat.Count = stat.StemCount + stat.HiddenCount
+		stat.Status = s.status(stat)
+		stat.Level = s.level(stat.Score)
+		profile.Stats = append(profile.Stats, *stat)
+	}
+
+	profile.Flags = s.flags(stats)
+	profile.Traits = s.traits(stats)
+	profile.Summary = s.summarize(profile)
+	return profile
+}
+
+// status 透藏状态：透出有根、透出（无根）、藏（不透）、不现
+func (s *ShiShenService) status(stat *models.ShiShenStat) string {
+	switch {
+	case stat.StemCount > 0 && stat.HiddenCount > 0:
+		return "透出有根"
+	case stat.StemCount > 0:
+		return "透出"
+	case stat.HiddenCount > 0:
+		return "藏"
+	default:
+		return "不现"
+	}
+}
+
+// level 力量分级
+func (s *ShiShenService) level(score int) string {
+	switch {
+	case score == 0:
+		return "无"
+	case score < 60:
+		return "弱"
+	case score < 180:
+		return "中"
+	default:
+		return "旺"
+	}
+}
+
+// flags 标注缺失、过旺及常见十神组合
+func (s *ShiShenService) flags(stats map[string]*models.ShiShenStat) []string {
+	flags := []string{}
+
+	for _, group := range shiShenGroups {
+		if stats[group[1]].Count == 0 && stats[group[2]].Count == 0 {
+			flags = append(flags, "无"+group[0])
+		}
+	}
+	for _, shiShen := range shiShenOrder {
+		if stats[shiShen].Level == "旺" {
+			flags = append(flags, shiShen+"旺")
+		}
+	}
+
+	revealed := func(shiShen string) bool { return stats[shiShen].StemCount > 0 }
+	if revealed("正官") && revealed("七杀") {
+		flags = append(flags, "官杀混杂")
+	}
+	if revealed("伤官") && revealed("正官") {
+		flags = append(flags, "伤官见官")
+	}
+	if stats["偏印"].Level == "旺" && stats["食神"].Count > 0 {
+		flags = append(flags, "枭神夺食")
+	}
+	if revealed("食神") && revealed("七杀") {
+		flags = append(flags, "食神制杀")
+	}
+
+	return flags
+}
+
+// traits 按透出或力量显著的十神及缺失的十神分组取性格特质
+func (s *ShiShenService) traits(stats map[string]*models.ShiShenStat) []models.ShiShenTrait {
+	traits := []models.ShiShenTrait{}
+
+	for _, shiShen := range shiShenOrder {
+		stat := stats[shiShen]
+		if stat.StemCount == 0 && stat.Level != "旺" {
+			continue
+		}
+		trait := shiShenTraits[shiShen]
+		condition := stat.Status
+		if stat.Level == "旺" {
+			condition += "且旺"
+		}
+		traits = append(traits, models.ShiShenTrait{
+			Source:    shiShen,
+			Condition: condition,
+			Positive:  trait.positive,
+			Negative:  trait.negative,
+		})
+	}
+
+	for _, group := range shiShenGroups {
+		if stats[group[1]].Count > 0 || stats[group[2]].Count > 0 {
+			continue
+		}
+		trait := shiShenAbsenceTraits[group[0]]
+		traits = append(traits, models.ShiShenTrait{
+			Source:    group[0],
+			Condition: "缺失",
+			Positive:  trait.positive,
+			Negative:  trait.negative,
+		})
+	}
+
+	return traits
+}
+
+// summarize 汇总性格结论
+func (s *ShiShenService) summarize(profile *models.ShiShenProfile) string {
+	if len(profile.Traits) == 0 {
+		return "十神分布均衡，性格无明显偏向"
+	}
+
+	parts := []string{}
+	for _, trait := range profile.Traits {
+		parts = append(parts, fmt.Sprintf("%s%s：%s，但%s", trait.Source, trait.Condition,
+			strings.Join(trait.Positive, "、"), strings.Join(trait.Negative, "、")))
+	}
+	return strings.Join(parts, "；")
+}
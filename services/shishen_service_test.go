@@ -0,0 +1,37 @@
+package services
+
+import (
+	"auspire/models"
+	"testing"
+)
+
+func TestShiShenAnalyzeFromBarePillars(t *testing.T) {
+	bazi := bareChart()
+	// 调用方传入的主星、副星不予采信
+	bazi[0].ZhuXing = "比肩"
+	bazi[0].CangGan = []models.CangGanItem{{Gan: "癸", ShiShen: "比肩", Weight: 100}}
+
+	profile := NewShiShenService().Analyze(bazi, CalcOptions{})
+
+	want := map[string]struct {
+		status string
+		score  int
+	}{
+		// 丁透于时干，午藏丁 70、未藏丁 10
+		"偏财": {"透出有根", 180},
+		// 庚透于年干，申藏庚为月令加倍 120、巳藏庚 30
+		"正印": {"透出有根", 250},
+		"伤官": {"透出", 100},
+		"比肩": {"不现", 0},
+	}
+	for _, stat := range profile.Stats {
+		if expected, exists := want[stat.ShiShen]; exists && (stat.Status != expected.status || stat.Score != expected.score) {
+			t.Errorf("%s = %s %d, want %s %d", stat.ShiShen, stat.Status, stat.Score, expected.status, expected.score)
+		}
+	}
+	for _, flag := range profile.Flags {
+		if flag == "无财星" || flag == "无印星" || flag == "无官杀" {
+			t.Errorf("unexpected flag %s in %v", flag, profile.Flags)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package solarterm
 
 import (
+	"math"
 	"time"
 )
 
@@ -173,9 +174,119 @@ func GetSolarTerm(date time.Time) string {
 }
 
 // 获取月柱地支（基于节气）
+//
+// 以当日之前最近的"节"（立春、惊蛰……小寒）定月支，节当日即属新月。
 func GetMonthDiZhi(date time.Time) string {
-	// 实际应用中需要更复杂的算法来精确计算节气日期
-	// 这里使用简化的实现
-	solarTerm := GetSolarTerm(date)
-	return GetDiZhiFromSolarTerm(solarTerm)
-}
\ No newline at end of file
+	term, _ := PrevJie(date)
+	return GetDiZhiFromSolarTerm(term)
+}
+
+// 十二节（每月之首）按公历月份排列，1 月小寒起
+var jieTerms = []string{
+	Xiaohan, Lichun, Jingzhe, Qingming, Lixia, Mangzhong,
+	Xiaoshu, Liqiu, Bailu, Hanlu, Lidong, Daxue,
+}
+
+// 十二中气按公历月份排列，1 月大寒起
+var zhongQiTerms = []string{
+	Dahan, Yushui, Chunfen, Guyu, Xiaoman, Xiazhi,
+	Dashu, Chushu, Qiufen, Shuangjiang, Xiaoxue, Dongzhi,
+}
+
+// 寿星通式 C 值：[20 世纪, 21 世纪]
+var termCValues = map[string][2]float64{
+	Xiaohan: {6.11, 5.4055}, Dahan: {20.84, 20.12},
+	Lichun: {4.6295, 3.87}, Yushui: {19.4599, 18.73},
+	Jingzhe: {6.3826, 5.63}, Chunfen: {21.4155, 20.646},
+	Qingming: {5.59, 4.81}, Guyu: {20.888, 20.1},
+	Lixia: {6.318, 5.52}, Xiaoman: {21.86, 21.04},
+	Mangzhong: {6.5, 5.678}, Xiazhi: {22.20, 21.37},
+	Xiaoshu: {7.928, 7.108}, Dashu: {23.65, 22.83},
+	Liqiu: {8.35, 7.5}, Chushu: {23.95, 23.13},
+	Bailu: {8.44, 7.646}, Qiufen: {23.822, 23.042},
+	Hanlu: {9.098, 8.318}, Shuangjiang: {24.218, 23.438},
+	Lidong: {8.218, 7.438}, Xiaoxue: {23.08, 22.36},
+	Daxue: {7.9, 7.18}, Dongzhi: {22.60, 21.94},
+}
+
+// TermDate 计算指定年份某节气的公历日期
+//
+// 采用寿星通式：日 = [Y×0.2422 + C] − [L]，Y 为年份后两位，L 为闰年数
+// （小寒、大寒、立春、雨水以 Y−1 计）。适用于 1900—2099 年，误差约一日，
+// 超出范围时按最近世纪的 C 值推算。
+func TermDate(year int, term string) time.Time {
+	month := termMonth(term)
+	century := 1
+	if year < 2000 {
+		century = 0
+	}
+	y := float64(year % 100)
+	leapBase := y
+	if month <= 2 {
+		leapBase = y - 1
+	}
+	day := int(math.Floor(y*0.2422+termCValues[term][century]) - math.Floor(leapBase/4))
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// termMonth 节气所在的公历月份
+func termMonth(term string) int {
+	for i := range jieTerms {
+		if jieTerms[i] == term || zhongQiTerms[i] == term {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// PrevJie 返回不晚于指定日期的最近一个"节"及其日期
+func PrevJie(date time.Time) (string, time.Time) {
+	day := truncateDay(date)
+	for year := day.Year(); year >= day.Year()-1; year-- {
+		for i := len(jieTerms) - 1; i >= 0; i-- {
+			if t := TermDate(year, jieTerms[i]); !t.After(day) {
+				return jieTerms[i], t
+			}
+		}
+	}
+	return Daxue, TermDate(day.Year()-1, Daxue)
+}
+
+// NextJie 返回晚于指定日期的下一个"节"及其日期
+func NextJie(date time.Time) (string, time.Time) {
+	day := truncateDay(date)
+	for year := day.Year(); year <= day.Year()+1; year++ {
+		for _, term := range jieTerms {
+			if t := TermDate(year, term); t.After(day) {
+				return term, t
+			}
+		}
+	}
+	return Xiaohan, TermDate(day.Year()+1, Xiaohan)
+}
+
+// PrevZhongQi 返回不晚于指定日期的最近一个中气及其日期
+func PrevZhongQi(date time.Time) (string, time.Time) {
+	day := truncateDay(date)
+	for year := day.Year(); year >= day.Year()-1; year-- {
+		for i := len(zhongQiTerms) - 1; i >= 0; i-- {
+			if t := TermDate(year, zhongQiTerms[i]); !t.After(day) {
+				return zhongQiTerms[i], t
+			}
+		}
+	}
+	return Dongzhi, TermDate(day.Year()-1, Dongzhi)
+}
+
+// GetBaziYear 以立春为岁首返回八字年份（立春前属上一年）
+func GetBaziYear(date time.Time) int {
+	if truncateDay(date).Before(TermDate(date.Year(), Lichun)) {
+		return date.Year() - 1
+	}
+	return date.Year()
+}
+
+// truncateDay 取日期部分（UTC 零点），节气比较只精确到日
+func truncateDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
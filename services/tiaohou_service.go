@@ -0,0 +1,163 @@
+package services
+
+import (
+	"auspire/models"
+	"strings"
+)
+
+// TiaoHouService 调候用神服务（据《穷通宝鉴》）
+type TiaoHouService struct {
+	cangGanService *CangGanService
+}
+
+var (
+	// 调候用神表：日干 × 月支
+	// 每项首字为主用调候，其余按先后为次用（佐用）
+	tiaoHouData = map[string]map[string]string{
+		"甲": {
+			"寅": "丙癸", "卯": "庚丙丁戊己", "辰": "庚丁壬", "巳": "癸丁庚", "午": "癸丁庚", "未": "癸丁庚",
+			"申": "庚丁壬", "酉": "庚丁丙", "戌": "庚甲丁壬癸", "亥": "庚丁丙戊", "子": "丁庚丙", "丑": "丁庚丙",
+		},
+		"乙": {
+			"寅": "丙癸", "卯": "丙癸", "辰": "癸丙戊", "巳": "癸", "午": "癸丙", "未": "癸丙",
+			"申": "丙癸己", "酉": "癸丙丁", "戌": "癸辛", "亥": "丙戊", "子": "丙", "丑": "丙",
+		},
+		"丙": {
+			"寅": "壬庚", "卯": "壬己", "辰": "壬甲", "巳": "壬癸庚", "午": "壬庚", "未": "壬庚",
+			"申": "壬戊", "酉": "壬癸", "戌": "甲壬", "亥": "甲戊庚壬", "子": "壬戊己", "丑": "壬甲",
+		},
+		"丁": {
+			"寅": "甲庚", "卯": "庚甲", "辰": "甲庚", "巳": "甲庚", "午": "壬庚癸", "未": "甲壬庚",
+			"申": "甲庚丙戊", "酉": "甲庚丙戊", "戌": "甲庚戊", "亥": "甲庚", "子": "甲庚", "丑": "甲庚",
+		},
+		"戊": {
+			"寅": "丙甲癸", "卯": "丙甲癸", "辰": "甲丙癸", "巳": "甲丙癸", "午": "壬甲丙", "未": "癸丙甲",
+			"申": "丙癸甲", "酉": "丙癸", "戌": "甲丙癸", "亥": "甲丙", "子": "丙甲", "丑": "丙甲",
+		},
+		"己": {
+			"寅": "丙庚甲", "卯": "甲癸丙", "辰": "丙癸甲", "巳": "癸丙", "午": "癸丙", "未": "癸丙",
+			"申": "丙癸", "酉": "丙癸", "戌": "甲丙癸", "亥": "丙甲戊", "子": "丙甲戊", "丑": "丙甲戊",
+		},
+		"庚": {
+			"寅": "戊甲壬丙丁", "卯": "丁甲庚丙", "辰": "甲丁壬癸", "巳": "壬戊丙丁", "午": "壬癸", "未": "丁甲",
+			"申": "丁甲", "酉": "丁甲丙", "戌": "甲壬", "亥": "丁丙", "子": "丁甲丙", "丑": "丙丁甲",
+		},
+		"辛": {
+			"寅": "己壬庚", "卯": "壬甲", "辰": "壬甲", "巳": "壬甲癸", "午": "壬己癸", "未": "壬庚甲",
+			"申": "壬甲戊", "酉": "壬甲", "戌": "壬甲", "亥": "壬丙", "子": "丙戊壬甲", "丑": "丙壬戊己",
+		},
+		"壬": {
+			"寅": "庚丙戊", "卯": "戊辛庚", "辰": "甲庚", "巳": "壬辛庚癸", "午": "癸庚辛", "未": "辛甲",
+			"申": "戊丁", "酉": "甲庚", "戌": "甲丙", "亥": "戊丙庚", "子": "戊丙", "丑": "丙丁甲",
+		},
+		"癸": {
+			"寅": "辛丙", "卯": "庚辛", "辰": "丙辛甲", "巳": "辛", "午": "庚辛壬癸", "未": "庚辛壬癸",
+			"申": "丁", "酉": "辛丙", "戌": "辛甲壬癸", "亥": "庚辛戊丁", "子": "丙辛", "丑": "丙丁",
+		},
+	}
+)
+
+func NewTiaoHouService() *TiaoHouService {
+	return newTiaoHouService(defaultSchoolProfile())
+}
+
+func newTiaoHouService(profile *SchoolProfile) *TiaoHouService {
+	return &TiaoHouService{
+		cangGanService: newCangGanService(profile),
+	}
+}
+
+// Calculate 计算调候用神，并检查其在命局中透干或藏支的情况
+func (s *TiaoHouService) Calculate(bazi []models.BaziColumn) *models.TiaoHouResult {
+	riZhu := bazi[2].Gan
+	yueZhi := bazi[1].Zhi
+
+	result := &models.TiaoHouResult{
+		RiZhu:     riZhu,
+		YueZhi:    yueZhi,
+		Primary:   []string{},
+		Secondary: []string{},
+		Items:     []models.TiaoHouShen{},
+		Source:    "穷通宝鉴",
+	}
+
+	entry, exists := tiaoHouData[riZhu][yueZhi]
+	if !exists {
+		result.Summary = "日干或月支无效，无法查得调候用神"
+		return result
+	}
+
+	for i, r := range []rune(entry) {
+		gan := string(r)
+		role := "次用"
+		if i == 0 {
+			role = "主用"
+			result.Primary = append(result.Primary, gan)
+		} else {
+			result.Secondary = append(result.Secondary, gan)
+		}
+		result.Items = append(result.Items, s.locate(gan, role, bazi))
+	}
+
+	result.Satisfied = result.Items[0].Exposed || result.Items[0].Hidden
+	result.Summary = s.summarize(result)
+
+	return result
+}
+
+// locate 查找调候用神在命局中的位置
+func (s *TiaoHouService) locate(gan, role string, bazi []models.BaziColumn) models.TiaoHouShen {
+	columnNames := []string{"年", "月", "日", "时"}
+	item := models.TiaoHouShen{
+		Gan:    gan,
+		WuXing: tianGanWuXing[gan],
+		Role:   role,
+	}
+
+	for i, column := range bazi {
+		// 日干为日主本身，不计入调候
+		if i != 2 && column.Gan == gan {
+			item.Exposed = true
+			item.Positions = append(item.Positions, columnNames[i]+"干")
+		}
+		// 藏干以地支查表为准，不依赖客户端传入
+		for _, cGan := range s.cangGanService.Calculate(column.Zhi) {
+			if cGan == gan {
+				item.Hidden = true
+				item.Positions = append(item.Positions, columnNames[i]+"支藏干")
+			}
+		}
+	}
+
+	return item
+}
+
+// summarize 生成调候结论
+func (s *TiaoHouService) summarize(result *models.TiaoHouResult) string {
+	var sb strings.Builder
+	sb.WriteString(result.RiZhu + tianGanWuXing[result.RiZhu] + "生于" + result.YueZhi + "月，调候以" + strings.Join(result.Primary, "") + "为主")
+	if len(result.Secondary) > 0 {
+		sb.WriteString("，" + strings.Join(result.Secondary, "、") + "为佐")
+	}
+	sb.WriteString("。")
+
+	for _, item := range result.Items {
+		switch {
+		case item.Exposed && item.Hidden:
+			sb.WriteString(item.Gan + "透干且有根；")
+		case item.Exposed:
+			sb.WriteString(item.Gan + "透干无根；")
+		case item.Hidden:
+			sb.WriteString(item.Gan + "藏而不透；")
+		default:
+			sb.WriteString(item.Gan + "不见；")
+		}
+	}
+
+	if result.Satisfied {
+		sb.WriteString("主用调候已在命局中出现。")
+	} else {
+		sb.WriteString("主用调候缺失，宜待运岁补足。")
+	}
+	return sb.String()
+}
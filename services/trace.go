@@ -0,0 +1,54 @@
+package services
+
+import "auspire/models"
+
+// CalcOptions 单次计算的可选参数
+type CalcOptions struct {
+	Explain bool           // 是否输出推导溯源（?explain=true）
+	Profile *SchoolProfile // 流派配置，为 nil 时使用默认流派
+}
+
+// profile 返回本次计算的流派配置
+func (o CalcOptions) profile() *SchoolProfile {
+	if o.Profile == nil {
+		return defaultSchoolProfile()
+	}
+	return o.Profile
+}
+
+// traceRecorder 推导溯源记录器
+//
+// 为 nil 时所有记录操作均为空操作，调用方无需判断是否开启溯源。
+type traceRecorder struct {
+	entries []models.TraceEntry
+}
+
+func newTraceRecorder(opts CalcOptions) *traceRecorder {
+	if !opts.Explain {
+		return nil
+	}
+	return &traceRecorder{entries: []models.TraceEntry{}}
+}
+
+// record 记录一条推导：由哪条规则、依据哪些输入、命中哪条表项得到何值
+func (t *traceRecorder) record(field, pillar, rule string, inputs map[string]string, match, value string) {
+	if t == nil {
+		return
+	}
+	t.entries = append(t.entries, models.TraceEntry{
+		Field:  field,
+		Pillar: pillar,
+		Rule:   rule,
+		Inputs: inputs,
+		Match:  match,
+		Value:  value,
+	})
+}
+
+// result 返回全部溯源记录，未开启时返回 nil
+func (t *traceRecorder) result() []models.TraceEntry {
+	if t == nil {
+		return nil
+	}
+	return t.entries
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,6 +14,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrUserNotFound 用户不存在
+var ErrUserNotFound = errors.New("用户不存在")
+
 type UserService struct {
 	redisClient *redis.Client
 	ctx         context.Context
@@ -162,4 +166,37 @@ func (s *UserService) GetUserByID(userID string) (*models.User, error) {
 
 	user.Password = "" // Don't return password
 	return user, nil
-}
\ No newline at end of file
+}
+
+// UpdateSchoolProfile 设置用户的默认流派
+func (s *UserService) UpdateSchoolProfile(userID, school string) (*models.User, error) {
+	if _, err := GetSchoolProfile(school); err != nil {
+		return nil, err
+	}
+
+	userData, err := s.redisClient.Get(s.ctx, fmt.Sprintf("user:%s", userID)).Result()
+	if err == redis.Nil {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("获取用户数据失败: %w", err)
+	}
+
+	user, err := models.UserFromJSON([]byte(userData))
+	if err != nil {
+		return nil, fmt.Errorf("解析用户数据失败: %w", err)
+	}
+
+	user.SchoolProfile = school
+	user.UpdatedAt = time.Now()
+
+	updated, err := user.ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("序列化用户数据失败: %w", err)
+	}
+	if err := s.redisClient.Set(s.ctx, fmt.Sprintf("user:%s", userID), updated, 0).Err(); err != nil {
+		return nil, fmt.Errorf("保存用户数据失败: %w", err)
+	}
+
+	user.Password = "" // Don't return password
+	return user, nil
+}
@@ -2,33 +2,58 @@ package services
 
 import (
 	"auspire/models"
+	"fmt"
 )
 
 // XiYongShenService 喜用神计算服务
 type XiYongShenService struct {
+	profile        *SchoolProfile
+	cangGanService *CangGanService
+	tiaoHouService *TiaoHouService
 }
 
 // NewXiYongShenService 创建新的喜用神服务实例
 func NewXiYongShenService() *XiYongShenService {
-	return &XiYongShenService{}
+	return newXiYongShenService(defaultSchoolProfile())
+}
+
+// newXiYongShenService 按流派创建喜用神服务，五行计分采用该流派的权重
+func newXiYongShenService(profile *SchoolProfile) *XiYongShenService {
+	return &XiYongShenService{
+		profile:        profile,
+		cangGanService: newCangGanService(profile),
+		tiaoHouService: newTiaoHouService(profile),
+	}
+}
+
+// withProfile 返回绑定指定流派的服务实例，流派相同时返回自身
+func (s *XiYongShenService) withProfile(profile *SchoolProfile) *XiYongShenService {
+	if profile == s.profile {
+		return s
+	}
+	return newXiYongShenService(profile)
 }
 
 // Calculate 计算喜用神
-func (s *XiYongShenService) Calculate(bazi []models.BaziColumn) *models.XiYongShenResult {
+func (s *XiYongShenService) Calculate(bazi []models.BaziColumn, opts CalcOptions) *models.XiYongShenResult {
+	s = s.withProfile(opts.profile())
+
 	result := &models.XiYongShenResult{
-		Logic: []string{},
+		Logic:  []string{},
+		School: s.profile.ID(),
 	}
+	trace := newTraceRecorder(opts)
 
 	// 获取日主（日柱天干）
 	riZhu := bazi[2].Gan
 	result.RiZhu = riZhu
 
 	// 计算五行得分
-	wuXingScores := s.calculateWuXingScores(bazi)
+	wuXingScores := s.calculateWuXingScores(bazi, trace)
 	result.WuXingScores = wuXingScores
 
 	// 判断日主强弱
-	riZhuStrength := s.determineRiZhuStrength(riZhu, wuXingScores)
+	riZhuStrength := s.determineRiZhuStrength(riZhu, wuXingScores, trace)
 	result.RiZhuStrength = riZhuStrength
 
 	// 确定喜用神
@@ -36,11 +61,23 @@ func (s *XiYongShenService) Calculate(bazi []models.BaziColumn) *models.XiYongSh
 	result.XiYongShen = xiYongShen
 	result.Logic = logic
 
+	// 以用神为核心划分五行角色，并标注四柱干支
+	wuXingRoles := s.classifyWuXingRoles(xiYongShen)
+	result.WuXingRoles = wuXingRoles
+	result.PillarRoles = s.labelPillars(bazi, wuXingRoles)
+	result.Logic = append(result.Logic, fmt.Sprintf("7. 五行角色：用神%s，喜神%s，忌神%s，仇神%s，闲神%s",
+		s.elementOfRole(wuXingRoles, "用神"), s.elementOfRole(wuXingRoles, "喜神"), s.elementOfRole(wuXingRoles, "忌神"),
+		s.elementOfRole(wuXingRoles, "仇神"), s.elementOfRole(wuXingRoles, "闲神")))
+
+	// 调候用神（独立于旺衰扶抑）
+	result.TiaoHou = s.tiaoHouService.Calculate(bazi)
+	result.Trace = trace.result()
+
 	return result
 }
 
 // calculateWuXingScores 计算五行得分
-func (s *XiYongShenService) calculateWuXingScores(bazi []models.BaziColumn) map[string]int {
+func (s *XiYongShenService) calculateWuXingScores(bazi []models.BaziColumn, trace *traceRecorder) map[string]int {
 	scores := map[string]int{
 		"木": 0,
 		"火": 0,
@@ -49,33 +86,61 @@ func (s *XiYongShenService) calculateWuXingScores(bazi []models.BaziColumn) map[
 		"水": 0,
 	}
 
-	// 统计天干地支中的五行数量
-	for _, column := range bazi {
+	// 按流派权重统计天干、地支及藏干的五行得分
+	weights := s.profile.StrengthWeights
+	columnNames := []string{"年柱", "月柱", "日柱", "时柱"}
+	for i, column := range bazi {
 		// 天干五行
-		scores[column.GanWuXing]++
+		scores[column.GanWuXing] += weights.TianGan
 
 		// 地支五行
-		scores[column.ZhiWuXing]++
+		scores[column.ZhiWuXing] += weights.DiZhi
 
-		// 考虑藏干的五行（简化处理，实际应该更复杂）
-		// 这里我们暂时只考虑天干地支的五行
+		trace.record("strength", columnNames[i], fmt.Sprintf("五行计分：天干计%d分、地支计%d分（%s）", weights.TianGan, weights.DiZhi, s.profile.ID()),
+			map[string]string{"天干": column.Gan, "地支": column.Zhi},
+			"tianGanWuXing["+column.Gan+"]="+column.GanWuXing+"；diZhiWuXing["+column.Zhi+"]="+column.ZhiWuXing,
+			fmt.Sprintf("%s+%d，%s+%d", column.GanWuXing, weights.TianGan, column.ZhiWuXing, weights.DiZhi))
+
+		// 藏干五行
+		if weights.CangGan > 0 {
+			for _, cGan := range s.cangGanService.Calculate(column.Zhi) {
+				scores[tianGanWuXing[cGan]] += weights.CangGan
+				trace.record("strength", columnNames[i], fmt.Sprintf("五行计分：藏干每字计%d分", weights.CangGan),
+					map[string]string{"地支": column.Zhi, "藏干": cGan},
+					"tianGanWuXing["+cGan+"]="+tianGanWuXing[cGan],
+					fmt.Sprintf("%s+%d", tianGanWuXing[cGan], weights.CangGan))
+			}
+		}
+	}
+
+	// 月令五行加权
+	if weights.YueLing > 0 && len(bazi) > 1 {
+		scores[bazi[1].ZhiWuXing] += weights.YueLing
+		trace.record("strength", "月柱", fmt.Sprintf("五行计分：月令五行另加%d分", weights.YueLing),
+			map[string]string{"月支": bazi[1].Zhi}, "diZhiWuXing["+bazi[1].Zhi+"]="+bazi[1].ZhiWuXing,
+			fmt.Sprintf("%s+%d", bazi[1].ZhiWuXing, weights.YueLing))
 	}
 
 	return scores
 }
 
 // determineRiZhuStrength 判断日主强弱
-func (s *XiYongShenService) determineRiZhuStrength(riZhu string, scores map[string]int) string {
+func (s *XiYongShenService) determineRiZhuStrength(riZhu string, scores map[string]int, trace *traceRecorder) string {
 	// 获取日主五行
 	riZhuWuXing := tianGanWuXing[riZhu]
 
 	// 简化的判断逻辑：
-	// 1. 如果日主五行在八字中出现次数>=2，则为偏强
-	// 2. 如果日主五行在八字中出现次数<2，则为偏弱
-	if scores[riZhuWuXing] >= 2 {
-		return "偏强"
+	// 1. 如果日主五行得分达到流派阈值，则为偏强
+	// 2. 否则为偏弱
+	threshold := s.profile.StrengthWeights.Threshold
+	strength := "偏弱"
+	if scores[riZhuWuXing] >= threshold {
+		strength = "偏强"
 	}
-	return "偏弱"
+	trace.record("strength", "日柱", fmt.Sprintf("日主强弱：日主五行得分>=%d为偏强，否则偏弱", threshold),
+		map[string]string{"日主": riZhu, "日主五行": riZhuWuXing},
+		fmt.Sprintf("wuXingScores[%s]=%d", riZhuWuXing, scores[riZhuWuXing]), strength)
+	return strength
 }
 
 // determineXiYongShen 确定喜用神
@@ -128,6 +193,98 @@ func (s *XiYongShenService) determineXiYongShen(riZhu, riZhuStrength string, sco
 	return xiYongShen, logic
 }
 
+// classifyWuXingRoles 以用神为核心划分五行角色
+// 用神：扶抑所取之五行；喜神：生用神者；忌神：克用神者；
+// 仇神：生忌神者；闲神：其余一行（用神所生）
+func (s *XiYongShenService) classifyWuXingRoles(yongShen string) map[string]string {
+	roles := make(map[string]string)
+	if s.getShengWuXing(yongShen) == "" {
+		return roles
+	}
+
+	xiShen := s.getShengWuXing(yongShen)
+	jiShen := s.getKeWuXing(yongShen)
+	chouShen := s.getShengWuXing(jiShen)
+
+	roles[yongShen] = "用神"
+	roles[xiShen] = "喜神"
+	roles[jiShen] = "忌神"
+	roles[chouShen] = "仇神"
+	for _, wuxing := range wuXingList {
+		if _, exists := roles[wuxing]; !exists {
+			roles[wuxing] = "闲神"
+		}
+	}
+
+	return roles
+}
+
+// labelPillars 按五行角色标注四柱天干、地支
+func (s *XiYongShenService) labelPillars(bazi []models.BaziColumn, roles map[string]string) []models.PillarRole {
+	columnNames := []string{"年柱", "月柱", "日柱", "时柱"}
+	labels := []models.PillarRole{}
+
+	for i, column := range bazi {
+		labels = append(labels, models.PillarRole{
+			Pillar:  columnNames[i],
+			Gan:     column.Gan,
+			GanRole: WuXingRoleOf(roles, column.Gan),
+			Zhi:     column.Zhi,
+			ZhiRole: WuXingRoleOf(roles, column.Zhi),
+		})
+	}
+
+	return labels
+}
+
+// elementOfRole 查找担任某角色的五行
+func (s *XiYongShenService) elementOfRole(roles map[string]string, role string) string {
+	for wuxing, r := range roles {
+		if r == role {
+			return wuxing
+		}
+	}
+	return ""
+}
+
+// WuXingRoleOf 查询天干或地支在五行角色表中的角色
+//
+// 供大运、流年等下游分析直接判断干支对原局的喜忌，无需重新推导。
+func WuXingRoleOf(roles map[string]string, ganOrZhi string) string {
+	wuxing, exists := tianGanWuXing[ganOrZhi]
+	if !exists {
+		wuxing = diZhiWuXing[ganOrZhi]
+	}
+	return roles[wuxing]
+}
+
+// IsFavorableRole 判断五行角色是否有利于命局（用神、喜神）
+func IsFavorableRole(role string) bool {
+	return role == "用神" || role == "喜神"
+}
+
+// IsUnfavorableRole 判断五行角色是否不利于命局（忌神、仇神）
+func IsUnfavorableRole(role string) bool {
+	return role == "忌神" || role == "仇神"
+}
+
+// rolesInChart 按五行角色表挑出他人八字中于我有利、不利的干支
+func rolesInChart(roles map[string]string, bazi []models.BaziColumn) (favorable, unfavorable []string) {
+	favorable, unfavorable = []string{}, []string{}
+	for _, column := range bazi {
+		for _, char := range []string{column.Gan, column.Zhi} {
+			role := WuXingRoleOf(roles, char)
+			switch {
+			case IsFavorableRole(role):
+				favorable = append(favorable, char)
+			case IsUnfavorableRole(role):
+				unfavorable = append(unfavorable, char)
+			}
+		}
+	}
+	return favorable, unfavorable
+}
+
 // getKeWuXing 获取克制某五行的五行
 func (s *XiYongShenService) getKeWuXing(wuxing string) string {
 	keRelations := map[string]string{
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ZeRiService 择日服务
+//
+// 在给定日期范围内按用事（嫁娶、搬家、开业、签约）挑选吉日吉时：
+// 先排除月破、岁破、建除忌用事之日及冲当事人日支、年支之日，
+// 再按建除宜忌、黄道黑道及当日干支对当事人用神的补益计分排序，每日另选黄道吉时。
+type ZeRiService struct {
+	profile           *SchoolProfile
+	baziService       *BaziService
+	xiYongShenService *XiYongShenService
+}
+
+var (
+	// 择日用事对应的黄历事项
+	zeRiActivities = map[string]string{"嫁娶": "嫁娶", "搬家": "搬家", "开业": "开市", "签约": "立券"}
+)
+
+const (
+	// 择日范围上限（天）
+	zeRiMaxDays = 366
+	// 默认返回的候选日数
+	zeRiDefaultLimit = 10
+	// 每日返回的吉时数
+	zeRiHoursPerDay = 3
+
+	// 计分：建除宜 +3、忌则排除；黄道 +3、黑道 -1；当事人喜用每字 +1、忌仇每字 -1
+	zeRiJianChuScore  = 3
+	zeRiHuangDaoScore = 3
+	zeRiHeiDaoScore   = -1
+)
+
+func NewZeRiService() *ZeRiService {
+	return newZeRiService(defaultSchoolProfile())
+}
+
+// newZeRiService 按流派创建择日服务，排盘及当事人喜用神均采用该流派
+func newZeRiService(profile *SchoolProfile) *ZeRiService {
+	return &ZeRiService{
+		profile:           profile,
+		baziService:       newBaziService(profile),
+		xiYongShenService: newXiYongShenService(profile),
+	}
+}
+
+// withProfile 返回绑定指定流派的服务实例，流派相同时返回自身
+func (s *ZeRiService) withProfile(profile *SchoolProfile) *ZeRiService {
+	if profile == s.profile {
+		return s
+	}
+	return newZeRiService(profile)
+}
+
+// zeRiParticipant 当事人：日支、年支及五行角色
+type zeRiParticipant struct {
+	name    string
+	riZhi   string
+	nianZhi string
+	roles   map[string]string
+}
+
+// Select 择日
+//
+// 日柱、月建取当日正午排盘；时辰取当日十二时辰（子时取当日零点至一点的早子时）。
+func (s *ZeRiService) Select(req models.ZeRiRequest, opts CalcOptions) (*models.ZeRiResult, error) {
+	s = s.withProfile(opts.profile())
+	chartOpts := CalcOptions{Profile: opts.Profile}
+
+	result := &models.ZeRiResult{
+		Purpose:    req.Purpose,
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+		Candidates: []models.ZeRiDay{},
+		School:     s.profile.ID(),
+	}
+
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		result.Error = fmt.Sprintf("开始日期格式错误: %v", err)
+		return result, err
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		result.Error = fmt.Sprintf("结束日期格式错误: %v", err)
+		return result, err
+	}
+	if end.Before(start) {
+		err = fmt.Errorf("结束日期不能早于开始日期")
+		result.Error = err.Error()
+		return result, err
+	}
+	if end.Sub(start).Hours()/24 >= zeRiMaxDays {
+		err = fmt.Errorf("择日范围不能超过%d天", zeRiMaxDays)
+		result.Error = err.Error()
+		return result, err
+	}
+
+	participants := []zeRiParticipant{}
+	for _, person := range req.Participants {
+		chart, err := s.baziService.CalculateBazi(person, chartOpts)
+		if err != nil {
+			result.Error = fmt.Sprintf("%s排盘失败: %s", person.Name, err.Error())
+			return result, err
+		}
+		xiYong := s.xiYongShenService.Calculate(chart.Bazi, chartOpts)
+		participants = append(participants, zeRiParticipant{
+			name:    person.Name,
+			riZhi:   chart.Bazi[2].Zhi,
+			nianZhi: chart.Bazi[0].Zhi,
+			roles:   xiYong.WuXingRoles,
+		})
+	}
+
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		bazi, err := s.baziService.calculateBaziColumns(date.Format("2006-01-02"), "12:00")
+		if err != nil {
+			result.Error = err.Error()
+			return result, err
+		}
+		if day, ok := s.evaluateDay(date, bazi, req.Purpose, participants); ok {
+			result.Candidates = append(result.Candidates, day)
+		} else {
+			result.Excluded++
+		}
+	}
+
+	sort.SliceStable(result.Candidates, func(i, j int) bool {
+		return result.Candidates[i].Score > result.Candidates[j].Score
+	})
+	limit := req.Limit
+	if limit <= 0 {
+		limit = zeRiDefaultLimit
+	}
+	if len(result.Candidates) > limit {
+		result.Candidates = result.Candidates[:limit]
+	}
+
+	return result, nil
+}
+
+// evaluateDay 评估一日，返回 false 表示该日应排除
+func (s *ZeRiService) evaluateDay(date time.Time, bazi []models.BaziColumn, purpose string, participants []zeRiParticipant) (models.ZeRiDay, bool) {
+	year, month, day := bazi[0], bazi[1], bazi[2]
+	jianChu := jianChuOf(month.Zhi, day.Zhi)
+	god := huangDaoGod(month.Zhi, day.Zhi)
+
+	candidate := models.ZeRiDay{
+		Date:     date.Format("2006-01-02"),
+		Year:     year.Gan + year.Zhi,
+		Month:    month.Gan + month.Zhi,
+		Day:      day.Gan + day.Zhi,
+		JianChu:  jianChu,
+		God:      god,
+		HuangDao: huangDaoJiShen[god],
+		Reasons:  []string{},
+		Hours:    []models.ZeRiHour{},
+	}
+
+	// 排除：岁破、建除忌用事（含月破）、冲当事人日支或年支
+	if isDiZhiChong(day.Zhi, year.Zhi) {
+		return candidate, false
+	}
+	switch jianChuVerdict(jianChu, zeRiActivities[purpose]) {
+	case -1:
+		return candidate, false
+	case 1:
+		candidate.Score += zeRiJianChuScore
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("%s日宜%s", jianChu, purpose))
+	default:
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("%s日于%s无妨", jianChu, purpose))
+	}
+	for _, person := range participants {
+		if isDiZhiChong(day.Zhi, person.riZhi) || isDiZhiChong(day.Zhi, person.nianZhi) {
+			return candidate, false
+		}
+	}
+
+	if candidate.HuangDao {
+		candidate.Score += zeRiHuangDaoScore
+		candidate.Reasons = append(candidate.Reasons, god+"黄道日")
+	} else {
+		candidate.Score += zeRiHeiDaoScore
+		candidate.Reasons = append(candidate.Reasons, god+"黑道日")
+	}
+
+	score, reasons := s.supply(day, participants, "日")
+	candidate.Score += score
+	candidate.Reasons = append(candidate.Reasons, reasons...)
+
+	candidate.Hours = s.selectHours(day, participants)
+	return candidate, true
+}
+
+// selectHours 选取当日黄道吉时：排除冲日支、冲当事人日支的时辰，按当事人喜用排序
+func (s *ZeRiService) selectHours(day models.BaziColumn, participants []zeRiParticipant) []models.ZeRiHour {
+	hours := []models.ZeRiHour{}
+
+	for i := range diZhi {
+		column := s.baziService.calculateHourColumn(day, i*2)
+		god := huangDaoGod(day.Zhi, column.Zhi)
+		if !huangDaoJiShen[god] || isDiZhiChong(column.Zhi, day.Zhi) {
+			continue
+		}
+		clash := false
+		for _, person := range participants {
+			if isDiZhiChong(column.Zhi, person.riZhi) {
+				clash = true
+				break
+			}
+		}
+		if clash {
+			continue
+		}
+
+		hour := models.ZeRiHour{
+			Zhi:     column.Zhi,
+			GanZhi:  column.Gan + column.Zhi,
+			Time:    shiChenRange(i),
+			God:     god,
+			Reasons: []string{god + "黄道时"},
+		}
+		score, reasons := s.supply(column, participants, "时")
+		hour.Score = score
+		hour.Reasons = append(hour.Reasons, reasons...)
+		hours = append(hours, hour)
+	}
+
+	sort.SliceStable(hours, func(i, j int) bool {
+		return hours[i].Score > hours[j].Score
+	})
+	if len(hours) > zeRiHoursPerDay {
+		hours = hours[:zeRiHoursPerDay]
+	}
+	return hours
+}
+
+// supply 干支对各当事人用神的补益：喜用每字 +1，忌仇每字 -1
+func (s *ZeRiService) supply(column models.BaziColumn, participants []zeRiParticipant, unit string) (int, []string) {
+	score := 0
+	reasons := []string{}
+
+	for _, person := range participants {
+		favorable, unfavorable := rolesInChart(person.roles, []models.BaziColumn{column})
+		score += len(favorable) - len(unfavorable)
+		for _, char := range favorable {
+			reasons = append(reasons, fmt.Sprintf("%s%s为%s%s", unit, char, person.name, WuXingRoleOf(person.roles, char)))
+		}
+		for _, char := range unfavorable {
+			reasons = append(reasons, fmt.Sprintf("%s%s为%s%s", unit, char, person.name, WuXingRoleOf(person.roles, char)))
+		}
+	}
+
+	return score, reasons
+}
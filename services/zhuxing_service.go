@@ -1,5 +1,7 @@
 package services
 
+import "fmt"
+
 // ZhuXingService 主星（十神）服务
 type ZhuXingService struct{}
 
@@ -57,6 +59,34 @@ func (s *ZhuXingService) Calculate(dayGan, targetGan string) string {
 	return ""
 }
 
+// Explain 说明十神判定依据（五行生克与阴阳异同）
+func (s *ZhuXingService) Explain(dayGan, targetGan string) string {
+	dayWuXing := tianGanWuXing[dayGan]
+	targetWuXing := tianGanWuXing[targetGan]
+
+	relation := ""
+	switch {
+	case dayWuXing == targetWuXing:
+		relation = "同我"
+	case s.isShengRelation(dayWuXing, targetWuXing):
+		relation = "我生"
+	case s.isKeRelation(dayWuXing, targetWuXing):
+		relation = "我克"
+	case s.isShengRelation(targetWuXing, dayWuXing):
+		relation = "生我"
+	case s.isKeRelation(targetWuXing, dayWuXing):
+		relation = "克我"
+	}
+
+	yinYang := "阴阳相同"
+	if s.getYinYang(dayGan) != s.getYinYang(targetGan) {
+		yinYang = "阴阳相异"
+	}
+
+	return fmt.Sprintf("%s(%s%s)→%s(%s%s)：%s，%s", dayGan, s.getYinYang(dayGan), dayWuXing,
+		targetGan, s.getYinYang(targetGan), targetWuXing, relation, yinYang)
+}
+
 // 获取天干阴阳
 func (s *ZhuXingService) getYinYang(gan string) string {
 	yangGan := []string{"甲", "丙", "戊", "庚", "壬"}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"auspire/models"
+	"fmt"
+	"time"
+)
+
+// ZiWeiService 紫微斗数排盘服务
+//
+// 以农历生年、月、日、时及性别起盘：定命宫、身宫，按五虎遁排十二宫宫干，
+// 取命宫纳音定五行局，再安紫微、天府两系十四主星及六吉、六煞、禄存、天马，
+// 依生年天干标四化，并按阳男阴女顺行、阴男阳女逆行排大限。
+type ZiWeiService struct {
+	baziService  *BaziService
+	naYinService *NaYinService
+}
+
+// ziWeiStarOffset 主星相对系首（紫微或天府）的宫位偏移
+type ziWeiStarOffset struct {
+	name   string
+	offset int
+}
+
+// ziWeiJu 五行局
+type ziWeiJu struct {
+	name   string
+	number int // 局数，亦为起运岁数
+}
+
+var (
+	// 十二宫（自命宫起逆行）
+	ziWeiPalaceNames = []string{"命宫", "兄弟", "夫妻", "子女", "财帛", "疾厄", "迁移", "交友", "官禄", "田宅", "福德", "父母"}
+
+	// 紫微系：自紫微逆行，紫微、天机、隔一太阳、武曲、天同、隔二廉贞
+	ziWeiSeries = []ziWeiStarOffset{{"紫微", 0}, {"天机", -1}, {"太阳", -3}, {"武曲", -4}, {"天同", -5}, {"廉贞", -8}}
+
+	// 天府系：自天府顺行，天府、太阴、贪狼、巨门、天相、天梁、七杀、隔三破军
+	tianFuSeries = []ziWeiStarOffset{{"天府", 0}, {"太阴", 1}, {"贪狼", 2}, {"巨门", 3}, {"天相", 4}, {"天梁", 5}, {"七杀", 6}, {"破军", 10}}
+
+	// 五行局：以命宫干支纳音五行定局
+	ziWeiJuTable = map[string]ziWeiJu{
+		"水": {"水二局", 2}, "木": {"木三局", 3}, "金": {"金四局", 4}, "土": {"土五局", 5}, "火": {"火六局", 6},
+	}
+
+	// 火星、铃星起宫（按年支三合局），自起宫顺数至生时
+	huoLingStart = map[string][2]string{
+		"寅午戌": {"丑", "卯"}, "申子辰": {"寅", "戌"}, "巳酉丑": {"卯", "戌"}, "亥卯未": {"酉", "戌"},
+	}
+
+	// 四化：生年天干所化禄、权、科、忌之星
+	siHuaTable = map[string][4]string{
+		"甲": {"廉贞", "破军", "武曲", "太阳"},
+		"乙": {"天机", "天梁", "紫微", "太阴"},
+		"丙": {"天同", "天机", "文昌", "廉贞"},
+		"丁": {"太阴", "天同", "天机", "巨门"},
+		"戊": {"贪狼", "太阴", "右弼", "天机"},
+		"己": {"武曲", "贪狼", "天梁", "文曲"},
+		"庚": {"太阳", "武曲", "太阴", "天同"},
+		"辛": {"巨门", "太阳", "文曲", "文昌"},
+		"壬": {"天梁", "紫微", "左辅", "武曲"},
+		"癸": {"破军", "巨门", "太阴", "贪狼"},
+	}
+	siHuaNames = []string{"化禄", "化权", "化科", "化忌"}
+
+	// 命主：按命宫地支
+	mingZhuTable = map[string]string{
+		"子": "贪狼", "丑": "巨门", "寅": "禄存", "卯": "文曲", "辰": "廉贞", "巳": "武曲",
+		"午": "破军", "未": "武曲", "申": "廉贞", "酉": "文曲", "戌": "禄存", "亥": "巨门",
+	}
+
+	// 身主：按生年地支
+	shenZhuTable = map[string]string{
+		"子": "火星", "丑": "天相", "寅": "天梁", "卯": "天同", "辰": "文昌", "巳": "天机",
+		"午": "火星", "未": "天相", "申": "天梁", "酉": "天同", "戌": "文昌", "亥": "天机",
+	}
+
+	// 农历月、日名称
+	lunarMonthNames = []string{"正月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "冬月", "腊月"}
+	lunarDayNames   = []string{
+		"初一", "初二", "初三", "初四", "初五", "初六", "初七", "初八", "初九", "初十",
+		"十一", "十二", "十三", "十四", "十五", "十六", "十七", "十八", "十九", "二十",
+		"廿一", "廿二", "廿三", "廿四", "廿五", "廿六", "廿七", "廿八", "廿九", "三十",
+	}
+)
+
+func NewZiWeiService() *ZiWeiService {
+	return &ZiWeiService{
+		baziService:  NewBaziService(),
+		naYinService: NewNaYinService(),
+	}
+}
+
+// Calculate 紫微斗数排盘
+//
+// 闰月前半月（十五日及以前）按本月、后半月按下月起盘；23 点后仍按当日子时。
+func (s *ZiWeiService) Calculate(req models.ZiWeiRequest) (*models.ZiWeiResult, error) {
+	birthTime, err := time.Parse("15:04", req.BirthTime)
+	if err != nil {
+		return &models.ZiWeiResult{Name: req.Name, Error: fmt.Sprintf("时间格式错误: %v", err)}, err
+	}
+	if req.IsLeapMonth && req.LunarMonth == 12 && req.LunarDay > 15 {
+		err = fmt.Errorf("农历无闰腊月后半月可转入的月份")
+		return &models.ZiWeiResult{Name: req.Name, Error: err.Error()}, err
+	}
+
+	month := req.LunarMonth
+	if req.IsLeapMonth && req.LunarDay > 15 {
+		month++
+	}
+	hour := ((birthTime.Hour() + 1) / 2) % 12
+	year := s.baziService.calculateYearColumn(req.LunarYear)
+	yearGanIndex := indexOf(tianGan, year.Gan)
+
+	// 命宫：自寅宫起正月顺数至生月，再自该宫起子时逆数至生时；身宫则顺数至生时
+	mingIndex := ((2+month-1-hour)%12 + 12) % 12
+	shenIndex := (2 + month - 1 + hour) % 12
+
+	// 十二宫地支与宫干（五虎遁）
+	palaceZhi := make([]int, len(ziWeiPalaceNames))
+	palaces := make([]models.ZiWeiPalace, len(ziWeiPalaceNames))
+	byZhi := map[int]*models.ZiWeiPalace{}
+	for i, name := range ziWeiPalaceNames {
+		palaceZhi[i] = (mingIndex - i + 12) % 12
+		zhi := diZhi[palaceZhi[i]]
+		palaces[i] = models.ZiWeiPalace{
+			Name:      name,
+			Gan:       s.baziService.calculateMonthGan(yearGanIndex, zhi),
+			Zhi:       zhi,
+			ShenGong:  palaceZhi[i] == shenIndex,
+			MainStars: []models.ZiWeiStar{},
+			Stars:     []models.ZiWeiStar{},
+		}
+		byZhi[palaceZhi[i]] = &palaces[i]
+	}
+
+	mingPalace := palaces[0]
+	ju := ziWeiJuTable[naYinWuXing(s.naYinService.Calculate(mingPalace.Gan, mingPalace.Zhi))]
+
+	// 十四主星
+	ziWeiIndex := s.ziWeiPosition(req.LunarDay, ju.number)
+	tianFuIndex := ((4-ziWeiIndex)%12 + 12) % 12
+	for _, star := range ziWeiSeries {
+		p := byZhi[((ziWeiIndex+star.offset)%12+12)%12]
+		p.MainStars = append(p.MainStars, models.ZiWeiStar{Name: star.name, Type: "主星"})
+	}
+	for _, star := range tianFuSeries {
+		p := byZhi[(tianFuIndex+star.offset)%12]
+		p.MainStars = append(p.MainStars, models.ZiWeiStar{Name: star.name, Type: "主星"})
+	}
+
+	// 辅星、煞星：天魁天钺、禄存、天马沿用神煞的天乙贵人、禄神、驿马表，
+	// 擎羊在禄存前一位，陀罗在后一位
+	sanHe := diZhiSanHe[year.Zhi]
+	kuiYue := shenShaTable("天乙贵人")[year.Gan]
+	luCun := indexOf(diZhi, shenShaTable("禄神")[year.Gan][0])
+	huoLing := huoLingStart[sanHe]
+	stars := []struct {
+		name, kind string
+		index      int
+	}{
+		{"左辅", "吉星", (4 + month - 1) % 12},
+		{"右弼", "吉星", ((10-(month-1))%12 + 12) % 12},
+		{"文昌", "吉星", (10 - hour + 12) % 12},
+		{"文曲", "吉星", (4 + hour) % 12},
+		{"天魁", "吉星", indexOf(diZhi, kuiYue[0])},
+		{"天钺", "吉星", indexOf(diZhi, kuiYue[1])},
+		{"禄存", "吉星", luCun},
+		{"天马", "吉星", indexOf(diZhi, shenShaTable("驿马")[sanHe][0])},
+		{"擎羊", "煞星", (luCun + 1) % 12},
+		{"陀罗", "煞星", (luCun + 11) % 12},
+		{"火星", "煞星", (indexOf(diZhi, huoLing[0]) + hour) % 12},
+		{"铃星", "煞星", (indexOf(diZhi, huoLing[1]) + hour) % 12},
+		{"地空", "煞星", (11 - hour + 12) % 12},
+		{"地劫", "煞星", (11 + hour) % 12},
+	}
+	for _, star := range stars {
+		p := byZhi[star.index]
+		p.Stars = append(p.Stars, models.ZiWeiStar{Name: star.name, Type: star.kind})
+	}
+
+	// 四化
+	siHua := []models.ZiWeiSiHua{}
+	for i, name := range siHuaTable[year.Gan] {
+		for p := range palaces {
+			for _, list := range [][]models.ZiWeiStar{palaces[p].MainStars, palaces[p].Stars} {
+				for k := range list {
+					if list[k].Name == name {
+						list[k].SiHua = siHuaNames[i]
+						siHua = append(siHua, models.ZiWeiSiHua{Star: name, Hua: siHuaNames[i], Palace: palaces[p].Name})
+					}
+				}
+			}
+		}
+	}
+
+	// 大限：自命宫起，阳男阴女顺行，阴男阳女逆行，每宫十年
+	direction := -1
+	if (yearGanIndex%2 == 0) == (req.Gender == "男") {
+		direction = 1
+	}
+	for k := 0; k < 12; k++ {
+		p := byZhi[((mingIndex+direction*k)%12+12)%12]
+		p.DaXianStart = ju.number + k*10
+		p.DaXianEnd = p.DaXianStart + 9
+	}
+
+	shenGong := ""
+	for _, p := range palaces {
+		if p.ShenGong {
+			shenGong = p.Name
+		}
+	}
+
+	return &models.ZiWeiResult{
+		Name:      req.Name,
+		Gender:    req.Gender,
+		LunarDate: s.lunarDate(req, year, hour),
+		YearGan:   year.Gan,
+		YearZhi:   year.Zhi,
+		MingGong:  mingPalace.Gan + mingPalace.Zhi,
+		ShenGong:  shenGong,
+		WuXingJu:  ju.name,
+		MingZhu:   mingZhuTable[mingPalace.Zhi],
+		ShenZhu:   shenZhuTable[year.Zhi],
+		SiHua:     siHua,
+		Palaces:   palaces,
+	}, nil
+}
+
+// ziWeiPosition 安紫微：以生日除以局数，商数不整时借数补足，
+// 借数为偶则自寅宫顺数商数后再进借数，为奇则退借数
+func (s *ZiWeiService) ziWeiPosition(day, ju int) int {
+	quotient := (day + ju - 1) / ju
+	borrow := quotient*ju - day
+	if borrow%2 == 0 {
+		return (2 + quotient - 1 + borrow) % 12
+	}
+	return ((2+quotient-1-borrow)%12 + 12) % 12
+}
+
+// lunarDate 农历生辰文本，如 "丙午年闰五月初八午时"
+func (s *ZiWeiService) lunarDate(req models.ZiWeiRequest, year models.BaziColumn, hour int) string {
+	leap := ""
+	if req.IsLeapMonth {
+		leap = "闰"
+	}
+	return fmt.Sprintf("%s%s年%s%s%s%s时", year.Gan, year.Zhi, leap, lunarMonthNames[req.LunarMonth-1], lunarDayNames[req.LunarDay-1], diZhi[hour])
+}
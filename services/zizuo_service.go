@@ -1,5 +1,7 @@
 package services
 
+import "fmt"
+
 // ZiZuoService 自坐服务
 type ZiZuoService struct {
 	cangGanService *CangGanService
@@ -7,16 +9,18 @@ type ZiZuoService struct {
 }
 
 func NewZiZuoService() *ZiZuoService {
+	return newZiZuoService(defaultSchoolProfile())
+}
+
+func newZiZuoService(profile *SchoolProfile) *ZiZuoService {
 	return &ZiZuoService{
-		cangGanService: NewCangGanService(),
-		xingYunService: NewXingYunService(),
+		cangGanService: newCangGanService(profile),
+		xingYunService: newXingYunService(profile),
 	}
 }
 
 // Calculate 计算自坐（天干在地支的状态）
 func (s *ZiZuoService) Calculate(gan, zhi string) string {
-	ganWuXing := tianGanWuXing[gan]
-	
 	// 查看地支藏干中是否有本天干
 	cangGan := s.cangGanService.Calculate(zhi)
 	for _, cGan := range cangGan {
@@ -26,10 +30,21 @@ func (s *ZiZuoService) Calculate(gan, zhi string) string {
 	}
 
 	// 计算天干在该地支的长生状态
-	changSheng := s.xingYunService.Calculate(ganWuXing, zhi)
+	changSheng := s.xingYunService.Calculate(gan, zhi)
 	if changSheng != "" {
 		return "自坐" + changSheng
 	}
 
 	return ""
-}
\ No newline at end of file
+}
+
+// Explain 说明自坐判定所命中的表项
+func (s *ZiZuoService) Explain(gan, zhi string) string {
+	cangGan := s.cangGanService.Calculate(zhi)
+	for _, cGan := range cangGan {
+		if cGan == gan {
+			return fmt.Sprintf("cangGanData[%s]=%v 含本干%s", zhi, cangGan, gan)
+		}
+	}
+	return fmt.Sprintf("cangGanData[%s]=%v 不含本干；十二长生(%s)[%s][%s]", zhi, cangGan, s.xingYunService.profile.changShengRule(), gan, zhi)
+}
@@ -158,8 +158,8 @@ class PaiPanApp {
         const cangganCells = cangganRow.querySelectorAll('.row-data');
         baziColumns.forEach((column, index) => {
             if (cangganCells[index] && column.cangGan) {
-                cangganCells[index].innerHTML = column.cangGan.map(gan => 
-                    `<span class="canggan ${this.getWuxingClass(this.getGanWuxing(gan))}">${gan}${this.getGanWuxing(gan)}</span>`
+                cangganCells[index].innerHTML = column.cangGan.map(item => 
+                    `<span class="canggan ${this.getWuxingClass(item.wuXing)}" title="${item.role} ${item.weight}%">${item.gan}${item.wuXing}</span>`
                 ).join('');
             }
         });
@@ -168,8 +168,8 @@ class PaiPanApp {
         const fuxingRow = rows[1];
         const fuxingCells = fuxingRow.querySelectorAll('.row-data');
         baziColumns.forEach((column, index) => {
-            if (fuxingCells[index] && column.fuXing) {
-                fuxingCells[index].textContent = column.fuXing.join('\n');
+            if (fuxingCells[index] && column.cangGan) {
+                fuxingCells[index].textContent = column.cangGan.map(item => item.shiShen).join('\n');
             }
         });
 
@@ -214,8 +214,8 @@ class PaiPanApp {
         const shenshaCells = shenshaRow.querySelectorAll('.row-data');
         baziColumns.forEach((column, index) => {
             if (shenshaCells[index] && column.shenSha) {
-                shenshaCells[index].innerHTML = Object.keys(column.shenSha).map(key => 
-                    `<div class="shensha-item">${key}</div>`
+                shenshaCells[index].innerHTML = column.shenSha.map(item => 
+                    `<div class="shensha-item ${this.getShenshaClass(item.category)}" title="${item.description || ''}">${item.name}</div>`
                 ).join('');
             }
         });
@@ -233,6 +233,16 @@ class PaiPanApp {
         return wuxingMap[wuxing] || '';
     }
 
+    // 获取神煞吉凶对应的CSS类
+    getShenshaClass(category) {
+        const categoryMap = {
+            '吉': 'shensha-ji',
+            '凶': 'shensha-xiong',
+            '中性': 'shensha-neutral'
+        };
+        return categoryMap[category] || '';
+    }
+
     // 获取天干对应的五行
     getGanWuxing(gan) {
         const ganWuxingMap = {
@@ -281,49 +291,62 @@ class PaiPanApp {
                     gan: "乙", zhi: "亥",
                     ganWuXing: "木", zhiWuXing: "水",
                     zhuXing: "比肩",
-                    cangGan: ["壬", "甲"],
-                    fuXing: ["正印", "劫财"],
+                    cangGan: [
+                        { gan: "壬", role: "本气", weight: 70, shiShen: "正印", wuXing: "水" },
+                        { gan: "甲", role: "中气", weight: 30, shiShen: "劫财", wuXing: "木" }
+                    ],
                     naYin: "山头火",
                     xingYun: "死",
                     ziZuo: "死",
                     kongWang: false,
-                    shenSha: { "国印": "亥" }
+                    shenSha: [{ name: "国印贵人", category: "吉", description: "主掌印信权柄，为人诚实，宜公职", basis: "日干", match: "亥" }]
                 },
                 {
                     gan: "甲", zhi: "申",
                     ganWuXing: "木", zhiWuXing: "金",
                     zhuXing: "劫财",
-                    cangGan: ["庚", "壬", "戊"],
-                    fuXing: ["正官", "正印", "正财"],
+                    cangGan: [
+                        { gan: "庚", role: "本气", weight: 60, shiShen: "正官", wuXing: "金" },
+                        { gan: "壬", role: "中气", weight: 30, shiShen: "正印", wuXing: "水" },
+                        { gan: "戊", role: "余气", weight: 10, shiShen: "正财", wuXing: "土" }
+                    ],
                     naYin: "泉中水",
                     xingYun: "胎",
                     ziZuo: "绝",
                     kongWang: false,
-                    shenSha: { "天乙": "申", "红艳": "申" }
+                    shenSha: [
+                        { name: "天乙贵人", category: "吉", description: "诸神之首，主逢凶化吉、得贵人扶助", basis: "日干、年干", match: "申" },
+                        { name: "红艳煞", category: "中性", description: "主多情风流、异性缘佳", basis: "日干", match: "申" }
+                    ]
                 },
                 {
                     gan: "乙", zhi: "亥",
                     ganWuXing: "木", zhiWuXing: "水",
                     zhuXing: "元男",
-                    cangGan: ["壬", "甲"],
-                    fuXing: ["正印", "劫财"],
+                    cangGan: [
+                        { gan: "壬", role: "本气", weight: 70, shiShen: "正印", wuXing: "水" },
+                        { gan: "甲", role: "中气", weight: 30, shiShen: "劫财", wuXing: "木" }
+                    ],
                     naYin: "山头火",
                     xingYun: "死",
                     ziZuo: "死",
                     kongWang: false,
-                    shenSha: { "国印": "亥", "十灵日": "亥" }
+                    shenSha: [{ name: "国印贵人", category: "吉", description: "主掌印信权柄，为人诚实，宜公职", basis: "日干、年干", match: "亥" }]
                 },
                 {
                     gan: "丙", zhi: "戌",
                     ganWuXing: "火", zhiWuXing: "土",
                     zhuXing: "伤官",
-                    cangGan: ["戊", "辛", "丁"],
-                    fuXing: ["正财", "七杀", "食神"],
+                    cangGan: [
+                        { gan: "戊", role: "本气", weight: 60, shiShen: "正财", wuXing: "土" },
+                        { gan: "丁", role: "中气", weight: 30, shiShen: "食神", wuXing: "火" },
+                        { gan: "辛", role: "余气", weight: 10, shiShen: "七杀", wuXing: "金" }
+                    ],
                     naYin: "屋上土",
                     xingYun: "墓",
                     ziZuo: "墓",
                     kongWang: false,
-                    shenSha: { "天喜": "戌", "龙德": "戌" }
+                    shenSha: [{ name: "天喜", category: "吉", description: "红鸾对宫，主喜庆、添丁", basis: "年支", match: "戌" }]
                 }
             ]
         };